@@ -0,0 +1,86 @@
+package pop_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/internal/pop"
+)
+
+func TestSign(t *testing.T) {
+	// The example of aliyun's doc for signing the request of sending SMS.
+	v := url.Values{}
+	v.Set("AccessKeyId", "testId")
+	v.Set("Action", "SendSms")
+	v.Set("Format", "XML")
+	v.Set("OutId", "123")
+	v.Set("PhoneNumbers", "15300000001")
+	v.Set("RegionId", "cn-hangzhou")
+	v.Set("SignName", "阿里云短信测试专用")
+	v.Set("SignatureMethod", "HMAC-SHA1")
+	v.Set("SignatureNonce", "45e25e9b-0a6f-4070-8c85-2956eda1b466")
+	v.Set("SignatureVersion", "1.0")
+	v.Set("TemplateCode", "SMS_71390007")
+	v.Set("TemplateParam", `{"customer":"test"}`)
+	v.Set("Timestamp", "2017-07-12T02:42:19Z")
+	v.Set("Version", "2017-05-25")
+
+	query := pop.CanonicalQuery(v)
+	wantQuery := "AccessKeyId=testId&Action=SendSms&Format=XML&OutId=123&PhoneNumbers=15300000001&RegionId=cn-hangzhou&SignName=%E9%98%BF%E9%87%8C%E4%BA%91%E7%9F%AD%E4%BF%A1%E6%B5%8B%E8%AF%95%E4%B8%93%E7%94%A8&SignatureMethod=HMAC-SHA1&SignatureNonce=45e25e9b-0a6f-4070-8c85-2956eda1b466&SignatureVersion=1.0&TemplateCode=SMS_71390007&TemplateParam=%7B%22customer%22%3A%22test%22%7D&Timestamp=2017-07-12T02%3A42%3A19Z&Version=2017-05-25"
+	if query != wantQuery {
+		t.Errorf("CanonicalQuery() = %v, want %v", query, wantQuery)
+	}
+
+	for _, signatureMethod := range []string{"", pop.HMACSHA1, "hmac-sha1"} {
+		got, err := pop.Sign("testSecret", "GET", query, signatureMethod)
+		if want := "zJDF+Lrzhj/ThnlvIToysFRq6t4="; got != want || err != nil {
+			t.Errorf("Sign() with %q = %v, %v, want %v", signatureMethod, got, err, want)
+		}
+	}
+}
+
+func TestSignSignatureMethod(t *testing.T) {
+	sha1Sig, err := pop.Sign("testSecret", "GET", "a=1", pop.HMACSHA1)
+	if err != nil {
+		t.Fatalf("Sign() with %v error: %v", pop.HMACSHA1, err)
+	}
+	sha256Sig, err := pop.Sign("testSecret", "GET", "a=1", pop.HMACSHA256)
+	if err != nil {
+		t.Fatalf("Sign() with %v error: %v", pop.HMACSHA256, err)
+	}
+	// 20 bytes of SHA1 and 32 bytes of SHA256 in base64.
+	if len(sha1Sig) != 28 || len(sha256Sig) != 44 {
+		t.Errorf("Sign() = %v, %v, want signatures of SHA1 and SHA256", sha1Sig, sha256Sig)
+	}
+
+	// Unsupported signature methods are never signed by HMAC-SHA1 silently.
+	for _, signatureMethod := range []string{"HMAC-SHA512", "HMAC_SHA256", "MD5"} {
+		if got, err := pop.Sign("testSecret", "GET", "a=1", signatureMethod); err == nil || got != "" {
+			t.Errorf("Sign() with %q = %v, %v, want error", signatureMethod, got, err)
+		}
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	v := url.Values{}
+	v.Set("b", "a b*c~")
+	v.Set("a", "")
+
+	if got, want := pop.CanonicalQuery(v), "a=&b=a%20b%2Ac~"; got != want {
+		t.Errorf("CanonicalQuery() = %v, want %v", got, want)
+	}
+}
+
+func TestStringToSign(t *testing.T) {
+	if got, want := pop.StringToSign("GET", "a=1&b=%20"), "GET&%2F&a%3D1%26b%3D%2520"; got != want {
+		t.Errorf("StringToSign() = %v, want %v", got, want)
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	tm := time.Date(2017, 7, 12, 10, 42, 19, 0, time.FixedZone("CST", 8*3600))
+	if got, want := pop.Timestamp(tm), "2017-07-12T02:42:19Z"; got != want {
+		t.Errorf("Timestamp() = %v, want %v", got, want)
+	}
+}
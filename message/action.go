@@ -0,0 +1,114 @@
+package message
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// DoAction calls any action of aliyun SMS service API which is not wrapped by this package. e.g. "AddSmsTemplate".
+// The request is signed in the same way as SendSMS().
+//
+// action: action name. e.g. "AddSmsTemplate", "QuerySmsSign".
+// extra: parameters of the action. e.g. {"SignName": "my_product"}. They override the default parameters.
+// params: optional parameters for the request. e.g. Version(), RegionID(), Format().
+//
+// It returns the parsed common response, the raw body of the HTTP response and error.
+// The response is nil if it can't be parsed. The raw body is returned if it's received.
+// The error is an *APIError if the status code of the response is not "OK".
+//
+// It's named DoAction but not Do to keep http.Client.Do() of the embedded http.Client.
+//
+// For example:
+//
+// c := message.NewClient(accessKeyID, accessKeySecret)
+//
+// resp, body, err := c.DoAction("QuerySmsSign", map[string]string{"SignName": "my_product"})
+func (c *Client) DoAction(action string, extra map[string]string, params ...Param) (*Response, []byte, error) {
+	return c.DoActionContext(context.Background(), action, extra, params...)
+}
+
+// DoActionContext calls the action with the context.
+// The HTTP request is aborted when the context is canceled or its deadline exceeds.
+// See DoAction() for other parameters.
+func (c *Client) DoActionContext(ctx context.Context, action string, extra map[string]string, params ...Param) (*Response, []byte, error) {
+	v, o, err := c.actionParams(action, extra, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response := &Response{}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return nil, result.body, err
+	}
+	return response, result.body, err
+}
+
+// BuildRequest returns the fully signed HTTP request of the action without sending it.
+// It's useful to snapshot-test the URL and the signature produced by a given set of parameters.
+// See DoAction() for parameters.
+// e.g. build the request of SendSMS():
+//
+//	req, err := c.BuildRequest(ctx, "SendSms", map[string]string{
+//		"PhoneNumbers":  "13800138000",
+//		"SignName":      "my_product",
+//		"TemplateCode":  "SMS_0000",
+//		"TemplateParam": `{"code":"1234"}`,
+//	}, message.Timestamp(t), message.SignatureNonce("nonce"))
+func (c *Client) BuildRequest(ctx context.Context, action string, extra map[string]string, params ...Param) (*http.Request, error) {
+	v, o, err := c.actionParams(action, extra, params)
+	if err != nil {
+		return nil, err
+	}
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
+		return nil, err
+	}
+	return c.newRequest(ctx, host, v, o)
+}
+
+// actionParams sets the default parameters, the parameters of the action and params in order.
+func (c *Client) actionParams(action string, extra map[string]string, params []Param) (url.Values, *requestOptions, error) {
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for SMS service.
+	v.Set("Action", action)
+	v.Set("Version", smsVersion)
+	v.Set("RegionId", DefaultRegionID)
+
+	// Merge parameters of the action.
+	for key, value := range extra {
+		v.Set(key, value)
+	}
+
+	// Override parameters if need.
+	o, err := applyParams(v, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return v, o, nil
+}
+
+// callAction calls the SMS action with the parameters of the action and params.
+// Parameters which only apply to voice calls are ignored.
+func (c *Client) callAction(ctx context.Context, action string, extra map[string]string, params []Param, response apiResponse) (callResult, error) {
+	v, o, err := c.actionParams(action, extra, params)
+	if err != nil {
+		return callResult{}, err
+	}
+	ignoreVoiceParams(v)
+
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
+		return callResult{}, err
+	}
+	return c.call(ctx, host, v, o, response)
+}
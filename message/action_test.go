@@ -0,0 +1,109 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestDoAction(t *testing.T) {
+	body := `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK","TemplateCode":"SMS_0000"}`
+
+	var req *http.Request
+	client := message.NewClient("testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, body), nil
+	})
+
+	extra := map[string]string{
+		"TemplateType":    "0",
+		"TemplateName":    "my template",
+		"TemplateContent": "您的验证码为：${code}",
+		"Remark":          "verification code",
+	}
+	resp, buf, err := client.DoAction("AddSmsTemplate", extra)
+	if err != nil {
+		t.Fatalf("DoAction() error: %v", err)
+	}
+	if resp.Code != "OK" || resp.RequestID != "8906582E-6722" {
+		t.Errorf("DoAction() response = %v, want code OK and request ID 8906582E-6722", resp)
+	}
+	if string(buf) != body {
+		t.Errorf("DoAction() body = %s, want %s", buf, body)
+	}
+
+	params, _ := sentParams(req)
+	if params["Action"] != "AddSmsTemplate" || params["Version"] != "2017-05-25" {
+		t.Errorf("Action = %v, Version = %v, want AddSmsTemplate, 2017-05-25", params["Action"], params["Version"])
+	}
+	for key, value := range extra {
+		if params[key] != value {
+			t.Errorf("%v = %v, want %v", key, params[key], value)
+		}
+	}
+
+	assertSigned(t, req, "testSecret")
+}
+
+func TestDoActionError(t *testing.T) {
+	body := `{"RequestId":"8906582E-6722","Code":"isv.SMS_TEMPLATE_ILLEGAL","Message":"template illegal"}`
+
+	client := message.NewClient("testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, body), nil
+	})
+
+	resp, buf, err := client.DoAction("QuerySmsTemplate", map[string]string{"TemplateCode": "SMS_0000"})
+	var apiErr *message.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "isv.SMS_TEMPLATE_ILLEGAL" {
+		t.Errorf("DoAction() error = %v, want *message.APIError of isv.SMS_TEMPLATE_ILLEGAL", err)
+	}
+	if resp == nil || resp.Code != "isv.SMS_TEMPLATE_ILLEGAL" {
+		t.Errorf("DoAction() response = %v, want the parsed response", resp)
+	}
+	if string(buf) != body {
+		t.Errorf("DoAction() body = %s, want %s", buf, body)
+	}
+}
+
+func TestBuildRequest(t *testing.T) {
+	sent := false
+	client := message.NewClient("testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		sent = true
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	timestamp, _ := time.Parse(time.RFC3339, "2017-07-12T02:42:19Z")
+	req, err := client.BuildRequest(context.Background(), "SendSms", map[string]string{
+		"PhoneNumbers":  "15300000001",
+		"SignName":      "阿里云短信测试专用",
+		"TemplateCode":  "SMS_71390007",
+		"TemplateParam": `{"customer":"test"}`,
+	},
+		message.Format("XML"),
+		message.OutID("123"),
+		message.Timestamp(timestamp),
+		message.SignatureNonce("45e25e9b-0a6f-4070-8c85-2956eda1b466"),
+	)
+	if err != nil {
+		t.Fatalf("BuildRequest() error: %v", err)
+	}
+	if sent {
+		t.Errorf("BuildRequest() sends the request")
+	}
+
+	// Same signature as the example of aliyun's doc.
+	want := "https://dysmsapi.aliyuncs.com/?Signature=zJDF%2BLrzhj%2FThnlvIToysFRq6t4%3D&AccessKeyId=testId&Action=SendSms&Format=XML&OutId=123&PhoneNumbers=15300000001&RegionId=cn-hangzhou&SignName=%E9%98%BF%E9%87%8C%E4%BA%91%E7%9F%AD%E4%BF%A1%E6%B5%8B%E8%AF%95%E4%B8%93%E7%94%A8&SignatureMethod=HMAC-SHA1&SignatureNonce=45e25e9b-0a6f-4070-8c85-2956eda1b466&SignatureVersion=1.0&TemplateCode=SMS_71390007&TemplateParam=%7B%22customer%22%3A%22test%22%7D&Timestamp=2017-07-12T02%3A42%3A19Z&Version=2017-05-25"
+	if req.Method != "GET" || req.URL.String() != want {
+		t.Errorf("BuildRequest() = %v %v, want GET %v", req.Method, req.URL, want)
+	}
+	if accept := req.Header.Get("Accept"); accept != "application/xml" {
+		t.Errorf("Accept = %v, want application/xml", accept)
+	}
+}
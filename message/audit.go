@@ -0,0 +1,50 @@
+package message
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+)
+
+// SendWithAudit sends the SMS to phone numbers and returns the signed request URL for auditing.
+// e.g. archive exactly what was sent for dispute resolution when customers claim they never received the SMS.
+//
+// See SendSMS() for parameters.
+//
+// It returns success status, response, the signed request URL and error.
+// The URL is the one of the last attempt which is sent and responded if the request is retried.
+// It contains the signature, the access key ID and the security token if any, but never the access key secret.
+// Parameters sent in the body for POST are in the query of the URL the same as GET.
+// The URL is empty if no request is sent and responded. e.g. invalid params, transport errors, the context is canceled.
+func (c *Client) SendWithAudit(ctx context.Context, phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, string, error) {
+	audited := ""
+	audit := Param{o: func(o *requestOptions) { o.signedURL = &audited }}
+
+	// Do not append to the backing array of the caller's params which may be shared.
+	params = append(params[:len(params):len(params)], audit)
+	ok, resp, err := c.SendSMSContext(ctx, phoneNumbers, signName, templateCode, templateParam, params...)
+	return ok, resp, audited, err
+}
+
+// signedURL returns the signed URL of the request.
+// Parameters in the body of POST are put in the query.
+func signedURL(req *http.Request) string {
+	if req.Method != http.MethodPost || req.GetBody == nil {
+		return req.URL.String()
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return req.URL.String()
+	}
+	defer body.Close()
+
+	buf, err := ioutil.ReadAll(body)
+	if err != nil {
+		return req.URL.String()
+	}
+
+	u := *req.URL
+	u.RawQuery = string(buf)
+	return u.String()
+}
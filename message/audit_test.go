@@ -0,0 +1,165 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestSendWithAudit(t *testing.T) {
+	query := "AccessKeyId=testId&Action=SendSms&Format=XML&OutId=123&PhoneNumbers=15300000001&RegionId=cn-hangzhou&SignName=%E9%98%BF%E9%87%8C%E4%BA%91%E7%9F%AD%E4%BF%A1%E6%B5%8B%E8%AF%95%E4%B8%93%E7%94%A8&SignatureMethod=HMAC-SHA1&SignatureNonce=45e25e9b-0a6f-4070-8c85-2956eda1b466&SignatureVersion=1.0&TemplateCode=SMS_71390007&TemplateParam=%7B%22customer%22%3A%22test%22%7D&Timestamp=2017-07-12T02%3A42%3A19Z&Version=2017-05-25"
+	timestamp, _ := time.Parse(time.RFC3339, "2017-07-12T02:42:19Z")
+
+	tests := []struct {
+		method string
+		// query is true if the parameters are sent in the query.
+		query bool
+	}{
+		{"GET", true},
+		// Parameters sent in the body are in the query of the signed URL too.
+		{"POST", false},
+	}
+
+	v := url.Values{}
+	for key, value := range docParams {
+		v.Set(key, value)
+	}
+
+	for _, tt := range tests {
+		var sent string
+		client := message.NewClient("testId", "testSecret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req.URL.String()
+			return newStubResponse(http.StatusOK, `<SendSmsResponse><Code>OK</Code></SendSmsResponse>`), nil
+		})
+
+		ok, _, signedURL, err := client.SendWithAudit(context.Background(), []string{"15300000001"}, "阿里云短信测试专用", "SMS_71390007", `{"customer":"test"}`,
+			message.Method(tt.method),
+			message.Format("XML"),
+			message.OutID("123"),
+			message.Timestamp(timestamp),
+			message.SignatureNonce("45e25e9b-0a6f-4070-8c85-2956eda1b466"),
+		)
+		if !ok || err != nil {
+			t.Fatalf("SendWithAudit() = %v, %v, want OK", ok, err)
+		}
+		// The signature of GET is the same as the example of aliyun's doc.
+		want := "https://dysmsapi.aliyuncs.com/?Signature=" + message.Signature(tt.method, v, "testSecret") + "&" + query
+		if signedURL != want {
+			t.Errorf("SendWithAudit(%v) URL = %v, want %v", tt.method, signedURL, want)
+		}
+		if (sent == signedURL) != tt.query {
+			t.Errorf("URL of the %v request = %v, signed URL = %v", tt.method, sent, signedURL)
+		}
+		if strings.Contains(signedURL, "testSecret") {
+			t.Errorf("signed URL contains the access key secret: %v", signedURL)
+		}
+	}
+}
+
+func TestSendWithAuditRetry(t *testing.T) {
+	var sent string
+	attempts := 0
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(2, time.Millisecond))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		sent = req.URL.String()
+		if attempts == 1 {
+			return newStubResponse(http.StatusServiceUnavailable, `{"Code":"ServiceUnavailable"}`), nil
+		}
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	ok, _, signedURL, err := client.SendWithAudit(context.Background(), []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if !ok || err != nil {
+		t.Fatalf("SendWithAudit() = %v, %v, want OK", ok, err)
+	}
+	// The signed URL is the one of the last attempt.
+	if attempts != 2 || signedURL != sent {
+		t.Errorf("SendWithAudit() URL = %v, want the URL of the last attempt %v", signedURL, sent)
+	}
+}
+
+func TestSendWithAuditNotSent(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("SendWithAudit() sends the request without phone numbers")
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	ok, _, signedURL, err := client.SendWithAudit(context.Background(), nil, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if ok || !errors.Is(err, message.ErrNoPhoneNumbers) || signedURL != "" {
+		t.Errorf("SendWithAudit() = %v, %q, %v, want false, empty URL, %v", ok, signedURL, err, message.ErrNoPhoneNumbers)
+	}
+}
+
+func TestSendWithAuditError(t *testing.T) {
+	errNetwork := errors.New("connection reset by peer")
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		ctx context.Context
+		err error
+	}{
+		// Transport error.
+		{context.Background(), errNetwork},
+		// The context is canceled.
+		{canceled, context.Canceled},
+	}
+
+	for _, tt := range tests {
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithMaxConcurrency(1))
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if err := req.Context().Err(); err != nil {
+				return nil, err
+			}
+			return nil, errNetwork
+		})
+
+		ok, _, signedURL, err := client.SendWithAudit(tt.ctx, []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+		if ok || !errors.Is(err, tt.err) || signedURL != "" {
+			t.Errorf("SendWithAudit() = %v, %q, %v, want false, empty URL, %v", ok, signedURL, err, tt.err)
+		}
+	}
+}
+
+func TestSendWithAuditSharedParams(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	// Shared params with spare capacity.
+	params := make([]message.Param, 0, 4)
+	params = append(params, message.Format("JSON"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		phoneNumber := fmt.Sprintf("1380013800%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, _, signedURL, err := client.SendWithAudit(context.Background(), []string{phoneNumber}, "my_product", "SMS_0000", `{"code":"1234"}`, params...)
+			if !ok || err != nil {
+				t.Errorf("SendWithAudit() = %v, %v, want OK", ok, err)
+				return
+			}
+
+			// Each caller gets its own signed URL.
+			u, _ := url.Parse(signedURL)
+			if got := u.Query().Get("PhoneNumbers"); got != phoneNumber {
+				t.Errorf("PhoneNumbers of the signed URL = %v, want %v", got, phoneNumber)
+			}
+		}()
+	}
+	wg.Wait()
+}
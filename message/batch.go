@@ -0,0 +1,89 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// SendBatchSMS sends the SMS with different signature names and template params to phone numbers in one request.
+//
+// phoneNumbers: phone numbers.
+// signNames: permitted signature names for each phone number.
+// templateCode: permitted template code shared by all phone numbers.
+// templateParams: JSON to render the template for each phone number. e.g. {"code":"1234"}.
+// params: optional parameters for sending batch SMS.
+//
+// The lengths of phoneNumbers, signNames and templateParams must be the same.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) SendBatchSMS(phoneNumbers, signNames []string, templateCode string, templateParams []string, params ...Param) (bool, *SMSResponse, error) {
+	return c.SendBatchSMSContext(context.Background(), phoneNumbers, signNames, templateCode, templateParams, params...)
+}
+
+// SendBatchSMSContext sends the batch SMS with the context.
+// See SendBatchSMS() for other parameters.
+func (c *Client) SendBatchSMSContext(ctx context.Context, phoneNumbers, signNames []string, templateCode string, templateParams []string, params ...Param) (bool, *SMSResponse, error) {
+	if len(phoneNumbers) != len(signNames) || len(phoneNumbers) != len(templateParams) {
+		return false, nil, fmt.Errorf("lengths of phone numbers(%d), sign names(%d) and template params(%d) mismatch",
+			len(phoneNumbers), len(signNames), len(templateParams))
+	}
+
+	phoneNumberJSON, err := json.Marshal(phoneNumbers)
+	if err != nil {
+		return false, nil, err
+	}
+
+	signNameJSON, err := json.Marshal(signNames)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Template params are JSON objects but not strings in the array.
+	rawParams := make([]json.RawMessage, len(templateParams))
+	for i, param := range templateParams {
+		if !json.Valid([]byte(param)) {
+			return false, nil, fmt.Errorf("invalid template param JSON at %d: %q", i, param)
+		}
+		rawParams[i] = json.RawMessage(param)
+	}
+	templateParamJSON, err := json.Marshal(rawParams)
+	if err != nil {
+		return false, nil, err
+	}
+
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for sending batch SMS.
+	v.Set("Action", "SendBatchSms")
+	v.Set("Version", smsVersion)
+	v.Set("RegionId", DefaultRegionID)
+
+	// Set required business parameters
+	v.Set("PhoneNumberJson", string(phoneNumberJSON))
+	v.Set("SignNameJson", string(signNameJSON))
+	v.Set("TemplateCode", templateCode)
+	v.Set("TemplateParamJson", string(templateParamJSON))
+
+	// Override parameters if need.
+	o, err := applyParams(v, params)
+	if err != nil {
+		return false, nil, err
+	}
+	ignoreVoiceParams(v)
+
+	response := &SMSResponse{}
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
+		return false, nil, err
+	}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
@@ -0,0 +1,68 @@
+package message_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestSendBatchSMS(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"Code":"OK","BizId":"134523^4351232"}`), nil
+	})
+
+	ok, resp, err := client.SendBatchSMS(
+		[]string{"13800138000", "13800138001"},
+		[]string{"签名A", "签名B"},
+		"SMS_0000",
+		[]string{`{"name":"a"}`, `{"name":"b"}`},
+	)
+	if err != nil || !ok || resp.BizID != "134523^4351232" {
+		t.Fatalf("SendBatchSMS() = %v, %v, %v, want OK response", ok, resp, err)
+	}
+
+	for key, want := range map[string]string{
+		"Action":            "SendBatchSms",
+		"PhoneNumberJson":   `["13800138000","13800138001"]`,
+		"SignNameJson":      `["签名A","签名B"]`,
+		"TemplateCode":      "SMS_0000",
+		"TemplateParamJson": `[{"name":"a"},{"name":"b"}]`,
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%v = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestSendBatchSMSInvalid(t *testing.T) {
+	requests := 0
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	tests := []struct {
+		phoneNumbers   []string
+		signNames      []string
+		templateParams []string
+	}{
+		{[]string{"13800138000", "13800138001"}, []string{"签名A"}, []string{`{}`, `{}`}},
+		{[]string{"13800138000"}, []string{"签名A"}, []string{`{}`, `{}`}},
+		{[]string{"13800138000"}, []string{"签名A"}, []string{`{"name":`}},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := client.SendBatchSMS(tt.phoneNumbers, tt.signNames, "SMS_0000", tt.templateParams); err == nil {
+			t.Errorf("SendBatchSMS(%v, %v, %v) returns no error", tt.phoneNumbers, tt.signNames, tt.templateParams)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("requests = %v, want 0", requests)
+	}
+}
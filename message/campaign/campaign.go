@@ -0,0 +1,227 @@
+// Package campaign runs managed bulk SMS sends built on message.Client.
+//
+// A campaign sends the SMS of the same sign name and template to a recipient list
+// at a limited rate with bounded concurrency. It can be paused, resumed and observed.
+package campaign
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+// Recipient is the recipient of a campaign.
+type Recipient struct {
+	// PhoneNumber is the phone number of the recipient.
+	PhoneNumber string
+	// TemplateParam is the JSON to render the template for the recipient. e.g. {"name":"Bob"}.
+	TemplateParam string
+}
+
+// Progress is the snapshot of the progress of a campaign.
+type Progress struct {
+	// Total is the number of recipients.
+	Total int
+	// Sent is the number of recipients the SMS is sent successfully to.
+	Sent int
+	// Failed is the number of recipients failed to send the SMS to.
+	Failed int
+	// Remaining is the number of recipients not sent yet.
+	Remaining int
+}
+
+// Campaign is a managed bulk send.
+type Campaign struct {
+	client       *message.Client
+	signName     string
+	templateCode string
+	recipients   []Recipient
+	// interval is the interval between sends limited by the rate. It's 0 for no limit.
+	interval    time.Duration
+	concurrency int
+	params      []message.Param
+
+	mu      sync.Mutex
+	started bool
+	// resume is closed when the campaign is running and open when it's paused.
+	resume chan struct{}
+	sent   int
+	failed int
+}
+
+// New creates a new campaign.
+//
+// client: the client used to send SMS.
+// signName: permitted signature name.
+// templateCode: permitted template code.
+// recipients: recipients with their template params.
+// rate: max number of SMS sent per second. rate <= 0 means no limit.
+// The interval between sends is at least 1ns, so a rate over 1e9 is clamped to 1e9.
+// concurrency: number of concurrent sends. It's 1 if concurrency <= 0.
+// params: optional parameters passed to each SendSMS() call.
+func New(client *message.Client, signName, templateCode string, recipients []Recipient, rate, concurrency int, params ...message.Param) *Campaign {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Second / time.Duration(rate)
+		// time.NewTicker() panics for non-positive intervals.
+		if interval < time.Nanosecond {
+			interval = time.Nanosecond
+		}
+	}
+
+	resume := make(chan struct{})
+	close(resume)
+
+	return &Campaign{
+		client:       client,
+		signName:     signName,
+		templateCode: templateCode,
+		recipients:   recipients,
+		interval:     interval,
+		concurrency:  concurrency,
+		params:       params,
+		resume:       resume,
+	}
+}
+
+// Start runs the campaign and blocks until all recipients are processed or the context is done.
+// In-flight sends are aborted when the context is done.
+// A campaign can only be started once.
+func (c *Campaign) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.started {
+		c.mu.Unlock()
+		return errors.New("campaign already started")
+	}
+	c.started = true
+	c.mu.Unlock()
+
+	jobs := make(chan Recipient)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < c.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				c.send(ctx, r)
+			}
+		}()
+	}
+
+	err := c.dispatch(ctx, jobs)
+	close(jobs)
+	wg.Wait()
+
+	return err
+}
+
+// dispatch feeds recipients to workers at the limited rate.
+func (c *Campaign) dispatch(ctx context.Context, jobs chan<- Recipient) error {
+	var tick <-chan time.Time
+	if c.interval > 0 {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for i, r := range c.recipients {
+		if err := c.waitResume(ctx); err != nil {
+			return err
+		}
+
+		// Send the first one without waiting for the ticker.
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case jobs <- r:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// waitResume blocks while the campaign is paused.
+func (c *Campaign) waitResume(ctx context.Context) error {
+	c.mu.Lock()
+	resume := c.resume
+	c.mu.Unlock()
+
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// send sends the SMS to the recipient and updates the progress.
+// The recipient remains if the send is aborted by the context.
+func (c *Campaign) send(ctx context.Context, r Recipient) {
+	ok, _, err := c.client.SendSMSContext(ctx, []string{r.PhoneNumber}, c.signName, c.templateCode, r.TemplateParam, c.params...)
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil || !ok {
+		c.failed++
+		return
+	}
+	c.sent++
+}
+
+// Pause pauses the campaign. Sends already in flight are not interrupted.
+func (c *Campaign) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.resume:
+		c.resume = make(chan struct{})
+	default:
+		// Already paused.
+	}
+}
+
+// Resume resumes the paused campaign.
+func (c *Campaign) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.resume:
+		// Already running.
+	default:
+		close(c.resume)
+	}
+}
+
+// Progress returns the snapshot of the progress.
+func (c *Campaign) Progress() Progress {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := len(c.recipients)
+	return Progress{
+		Total:     total,
+		Sent:      c.sent,
+		Failed:    c.failed,
+		Remaining: total - c.sent - c.failed,
+	}
+}
@@ -0,0 +1,111 @@
+package campaign_test
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/campaign"
+)
+
+// roundTripFunc is used to stub the HTTP round trip of the client in tests.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newStubClient returns a client which gets an error code for the phone number "10000000000"
+// and "OK" for others.
+func newStubClient() *message.Client {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body := `{"Code":"OK"}`
+		if req.URL.Query().Get("PhoneNumbers") == "10000000000" {
+			body = `{"Code":"isv.MOBILE_NUMBER_ILLEGAL"}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+	return client
+}
+
+var recipients = []campaign.Recipient{
+	{"13800138000", `{"name":"a"}`},
+	{"13800138001", `{"name":"b"}`},
+	{"10000000000", `{"name":"c"}`},
+	{"13800138003", `{"name":"d"}`},
+	{"13800138004", `{"name":"e"}`},
+}
+
+func TestCampaign(t *testing.T) {
+	c := campaign.New(newStubClient(), "my_product", "SMS_0000", recipients, 100, 2)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+
+	want := campaign.Progress{Total: 5, Sent: 4, Failed: 1, Remaining: 0}
+	if got := c.Progress(); got != want {
+		t.Errorf("Progress() = %+v, want %+v", got, want)
+	}
+
+	if err := c.Start(context.Background()); err == nil {
+		t.Errorf("Start() twice returns no error")
+	}
+}
+
+func TestCampaignHugeRate(t *testing.T) {
+	// The interval of the rate is less than 1ns.
+	c := campaign.New(newStubClient(), "my_product", "SMS_0000", recipients, math.MaxInt32, 2)
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+
+	want := campaign.Progress{Total: 5, Sent: 4, Failed: 1, Remaining: 0}
+	if got := c.Progress(); got != want {
+		t.Errorf("Progress() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCampaignPauseResume(t *testing.T) {
+	c := campaign.New(newStubClient(), "my_product", "SMS_0000", recipients, 0, 1)
+	c.Pause()
+
+	done := make(chan error)
+	go func() {
+		done <- c.Start(context.Background())
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if got := c.Progress(); got.Remaining != 5 {
+		t.Errorf("Progress() while paused = %+v, want 5 remaining", got)
+	}
+
+	c.Resume()
+	if err := <-done; err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if got := c.Progress(); got.Remaining != 0 || got.Sent != 4 {
+		t.Errorf("Progress() after resume = %+v, want 4 sent and 0 remaining", got)
+	}
+}
+
+func TestCampaignCancel(t *testing.T) {
+	c := campaign.New(newStubClient(), "my_product", "SMS_0000", recipients, 0, 1)
+	c.Pause()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := c.Start(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Start() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,190 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Fallback types of card SMS for the phone numbers which do not support card SMS.
+const (
+	// FallbackSMS falls back to the text SMS.
+	FallbackSMS = "SMS"
+	// FallbackDigitalSMS falls back to the digital SMS.
+	FallbackDigitalSMS = "DIGITALSMS"
+	// FallbackNone does not fall back.
+	FallbackNone = "NONE"
+)
+
+// CardObject is the phone number and the dynamic parameters of the card SMS.
+type CardObject struct {
+	// Mobile is the phone number. e.g. "13800138000".
+	Mobile string `json:"mobile"`
+	// DyncParams is the JSON to render the dynamic parameters of the card template. e.g. {"code":"1234"}.
+	DyncParams string `json:"dyncParams,omitempty"`
+	// CustomURL is the custom URL of the card.
+	CustomURL string `json:"customUrl,omitempty"`
+	// CustomShortCodeJSON is the JSON of custom short codes of the card.
+	CustomShortCodeJSON string `json:"customShortCodeJson,omitempty"`
+}
+
+// FallbackConfig is the config to fall back for the phone numbers which do not support card SMS.
+type FallbackConfig struct {
+	// Type is the fallback type: FallbackSMS, FallbackDigitalSMS or FallbackNone.
+	Type string
+	// SmsTemplateCode is the template code of the text SMS for FallbackSMS.
+	SmsTemplateCode string
+	// SmsTemplateParam is the JSON to render the template of the text SMS. e.g. {"code":"1234"}.
+	SmsTemplateParam string
+	// DigitalTemplateCode is the template code of the digital SMS for FallbackDigitalSMS.
+	DigitalTemplateCode string
+	// DigitalTemplateParam is the JSON to render the template of the digital SMS.
+	DigitalTemplateParam string
+}
+
+// CardSmsData is the result of sending the card SMS.
+type CardSmsData struct {
+	// BizCardID is the business ID of the card SMS.
+	BizCardID string `json:"BizCardId" xml:"BizCardId"`
+	// BizSmsID is the business ID of the fallback text SMS.
+	BizSmsID string `json:"BizSmsId" xml:"BizSmsId"`
+	// BizDigitalID is the business ID of the fallback digital SMS.
+	BizDigitalID string `json:"BizDigitalId" xml:"BizDigitalId"`
+	// CardTmpState is the audit status of the card template.
+	CardTmpState int `json:"CardTmpState" xml:"CardTmpState"`
+	// MediaMobiles are the phone numbers which support card SMS separated by ",".
+	MediaMobiles string `json:"MediaMobiles" xml:"MediaMobiles"`
+	// NotMediaMobiles are the phone numbers which do not support card SMS separated by ",".
+	NotMediaMobiles string `json:"NotMediaMobiles" xml:"NotMediaMobiles"`
+}
+
+// SendCardSmsResponse is the response of HTTP request of sending the card SMS.
+type SendCardSmsResponse struct {
+	Response
+	// Success is true if the request succeeds.
+	Success bool `json:"Success" xml:"Success"`
+	// Data is the result of sending the card SMS.
+	Data CardSmsData `json:"Data" xml:"Data"`
+}
+
+// SendCardSms sends the card SMS to the phone numbers of card objects.
+//
+// cardTemplateCode: permitted card template code.
+// cardObjects: phone numbers and dynamic parameters of the card SMS.
+// fallback: config to fall back for the phone numbers which do not support card SMS.
+// params: optional parameters for sending the card SMS. Use SignName() to specify the signature name.
+//
+// Use CheckMobilesCardSupport() to check the phone numbers before sending.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) SendCardSms(cardTemplateCode string, cardObjects []CardObject, fallback FallbackConfig, params ...Param) (bool, *SendCardSmsResponse, error) {
+	return c.SendCardSmsContext(context.Background(), cardTemplateCode, cardObjects, fallback, params...)
+}
+
+// SendCardSmsContext sends the card SMS with the context.
+// See SendCardSms() for other parameters.
+func (c *Client) SendCardSmsContext(ctx context.Context, cardTemplateCode string, cardObjects []CardObject, fallback FallbackConfig, params ...Param) (bool, *SendCardSmsResponse, error) {
+	cardObjectsJSON, err := json.Marshal(cardObjects)
+	if err != nil {
+		return false, nil, err
+	}
+
+	extra := map[string]string{
+		"CardTemplateCode": cardTemplateCode,
+		"CardObjects":      string(cardObjectsJSON),
+	}
+	for key, value := range map[string]string{
+		"FallbackType":         fallback.Type,
+		"SmsTemplateCode":      fallback.SmsTemplateCode,
+		"SmsTemplateParam":     fallback.SmsTemplateParam,
+		"DigitalTemplateCode":  fallback.DigitalTemplateCode,
+		"DigitalTemplateParam": fallback.DigitalTemplateParam,
+	} {
+		if value != "" {
+			extra[key] = value
+		}
+	}
+
+	response := &SendCardSmsResponse{}
+	result, err := c.callAction(ctx, "SendCardSms", extra, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// MobileCardSupport is the result of checking the phone number supports card SMS.
+type MobileCardSupport struct {
+	// Mobile is the phone number. e.g. "13800138000".
+	Mobile string `json:"mobile" xml:"mobile"`
+	// Support is true if the phone number supports card SMS.
+	Support bool `json:"support" xml:"support"`
+}
+
+// CheckMobilesCardSupportResponse is the response of HTTP request of checking phone numbers support card SMS.
+type CheckMobilesCardSupportResponse struct {
+	Response
+	// Success is true if the request succeeds.
+	Success bool `json:"Success" xml:"Success"`
+	// Templates are the results of phone numbers.
+	Templates []MobileCardSupport `json:"-" xml:"Data>templates"`
+}
+
+// UnmarshalJSON parses the JSON response.
+// Results of phone numbers are nested under "Data" > "templates".
+func (r *CheckMobilesCardSupportResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Response
+		Success bool `json:"Success"`
+		Data    struct {
+			Templates []MobileCardSupport `json:"templates"`
+		} `json:"Data"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	r.Response = raw.Response
+	r.Success = raw.Success
+	r.Templates = raw.Data.Templates
+	return nil
+}
+
+// CheckMobilesCardSupport checks if the phone numbers support the card SMS of the card template.
+// Route the phone numbers which do not support card SMS to the text SMS.
+//
+// It returns the map of phone numbers to the support status and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) CheckMobilesCardSupport(templateCode string, phoneNumbers []string, params ...Param) (map[string]bool, error) {
+	return c.CheckMobilesCardSupportContext(context.Background(), templateCode, phoneNumbers, params...)
+}
+
+// CheckMobilesCardSupportContext checks if the phone numbers support card SMS with the context.
+// See CheckMobilesCardSupport() for other parameters.
+func (c *Client) CheckMobilesCardSupportContext(ctx context.Context, templateCode string, phoneNumbers []string, params ...Param) (map[string]bool, error) {
+	mobiles := make([]map[string]string, len(phoneNumbers))
+	for i, phoneNumber := range phoneNumbers {
+		mobiles[i] = map[string]string{"mobile": phoneNumber}
+	}
+	mobilesJSON, err := json.Marshal(mobiles)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := map[string]string{
+		"TemplateCode": templateCode,
+		"Mobiles":      string(mobilesJSON),
+	}
+
+	response := &CheckMobilesCardSupportResponse{}
+	result, err := c.callAction(ctx, "CheckMobilesCardSupport", extra, params, response)
+	if !result.ok {
+		return nil, err
+	}
+
+	support := make(map[string]bool, len(response.Templates))
+	for _, t := range response.Templates {
+		support[t.Mobile] = t.Support
+	}
+	return support, nil
+}
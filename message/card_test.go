@@ -0,0 +1,103 @@
+package message_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestSendCardSms(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"RequestId":"CC89A90C-978F-46AC-B80D-54738371E7CA","Code":"OK","Message":"OK","Success":true,"Data":{"BizCardId":"123^0","BizSmsId":"456^0","CardTmpState":1,"MediaMobiles":"13800138000","NotMediaMobiles":"13800138001"}}`), nil
+	})
+
+	cardObjects := []message.CardObject{
+		{Mobile: "13800138000", DyncParams: `{"orderId":"1001"}`},
+		{Mobile: "13800138001", DyncParams: `{"orderId":"1002"}`, CustomURL: "https://example.com/1002"},
+	}
+	fallback := message.FallbackConfig{
+		Type:             message.FallbackSMS,
+		SmsTemplateCode:  "SMS_0000",
+		SmsTemplateParam: `{"orderId":"1002"}`,
+	}
+	ok, resp, err := client.SendCardSms("CARD_SMS_0000", cardObjects, fallback, message.SignName("my_product"))
+	if !ok || err != nil {
+		t.Fatalf("SendCardSms() = %v, %v, %v, want OK response", ok, resp, err)
+	}
+
+	for key, want := range map[string]string{
+		"Action":           "SendCardSms",
+		"CardTemplateCode": "CARD_SMS_0000",
+		"SignName":         "my_product",
+		"FallbackType":     "SMS",
+		"SmsTemplateCode":  "SMS_0000",
+		"SmsTemplateParam": `{"orderId":"1002"}`,
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%v = %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := query["DigitalTemplateCode"]; ok {
+		t.Errorf("DigitalTemplateCode = %v, want no digital template", query.Get("DigitalTemplateCode"))
+	}
+
+	var objects []map[string]string
+	if err := json.Unmarshal([]byte(query.Get("CardObjects")), &objects); err != nil {
+		t.Fatalf("CardObjects = %v, error: %v", query.Get("CardObjects"), err)
+	}
+	if len(objects) != 2 || objects[0]["mobile"] != "13800138000" || objects[0]["dyncParams"] != `{"orderId":"1001"}` ||
+		objects[1]["customUrl"] != "https://example.com/1002" {
+		t.Errorf("CardObjects = %v, want card objects of 13800138000 and 13800138001", objects)
+	}
+	if _, ok := objects[0]["customUrl"]; ok {
+		t.Errorf("CardObjects[0] = %v, want no empty customUrl", objects[0])
+	}
+
+	want := message.CardSmsData{BizCardID: "123^0", BizSmsID: "456^0", CardTmpState: 1, MediaMobiles: "13800138000", NotMediaMobiles: "13800138001"}
+	if !resp.Success || resp.Data != want {
+		t.Errorf("SendCardSms() data = %+v, want %+v", resp.Data, want)
+	}
+}
+
+func TestCheckMobilesCardSupport(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"RequestId":"08C17DFE-2E10-54F4-BAFB-7180039CC217","Code":"OK","Message":"OK","Success":true,"Data":{"templates":[{"mobile":"13800138000","support":true},{"mobile":"13800138001","support":false}]}}`), nil
+	})
+
+	support, err := client.CheckMobilesCardSupport("CARD_SMS_0000", []string{"13800138000", "13800138001"})
+	if err != nil {
+		t.Fatalf("CheckMobilesCardSupport() error: %v", err)
+	}
+	if query.Get("Action") != "CheckMobilesCardSupport" || query.Get("TemplateCode") != "CARD_SMS_0000" {
+		t.Errorf("Action = %v, TemplateCode = %v, want CheckMobilesCardSupport, CARD_SMS_0000", query.Get("Action"), query.Get("TemplateCode"))
+	}
+	if got, want := query.Get("Mobiles"), `[{"mobile":"13800138000"},{"mobile":"13800138001"}]`; got != want {
+		t.Errorf("Mobiles = %v, want %v", got, want)
+	}
+	if len(support) != 2 || !support["13800138000"] || support["13800138001"] {
+		t.Errorf("CheckMobilesCardSupport() = %v, want 13800138000 supported only", support)
+	}
+}
+
+func TestCheckMobilesCardSupportError(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"RequestId":"08C17DFE-2E10-54F4-BAFB-7180039CC217","Code":"isv.SMS_TEMPLATE_ILLEGAL","Message":"template illegal"}`), nil
+	})
+
+	support, err := client.CheckMobilesCardSupport("CARD_SMS_0000", []string{"13800138000"})
+	var apiErr *message.APIError
+	if !errors.As(err, &apiErr) || support != nil {
+		t.Errorf("CheckMobilesCardSupport() = %v, %v, want nil, *message.APIError", support, err)
+	}
+}
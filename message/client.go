@@ -1,110 +1,515 @@
 package message
 
 import (
-	"crypto/hmac"
-	"crypto/sha1"
-	"encoding/base64"
+	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/northbright/uuid"
+	"github.com/northbright/aliyun/internal/pop"
+)
+
+const (
+	// DefaultRegionID is the default region ID of aliyun message services.
+	DefaultRegionID = "cn-hangzhou"
+	// DefaultScheme is the default scheme of requests.
+	DefaultScheme = "https"
+	// DefaultUserAgent is the default User-Agent header of requests to identify this package.
+	DefaultUserAgent = "northbright-aliyun/" + sdkVersion
+	// sdkVersion is the version of this package.
+	sdkVersion = "1.0.0"
+	// smsHost is the host of aliyun SMS service API.
+	smsHost = "dysmsapi.aliyuncs.com"
+	// voiceHost is the host of aliyun voice messaging service API.
+	voiceHost = "dyvmsapi.aliyuncs.com"
+	// smsVersion is the default API version of aliyun SMS service.
+	smsVersion = "2017-05-25"
+	// voiceVersion is the default API version of aliyun voice messaging service.
+	voiceVersion = "2017-05-25"
 )
 
 // Client is used to make HTTP requests of aliyun API message serviices.
 // A client should be resused to send SMS, make single TTS call...
+// It's safe for concurrent use by multiple goroutines.
+// Parameters are built per request and never stored on the client.
 type Client struct {
 	// Use http.Client.Do().
+	// It remains exported for backward compatibility.
+	// Prefer WithHTTPClient() or WithTransport() to customize it.
 	http.Client
-	// accessKeyID is the access key ID generated by user.
-	accessKeyID string
-	// accessKeySecret is the access key secret generated by user.
-	accessKeySecret string
+	// credentials provides the credentials to sign requests.
+	credentials CredentialProvider
+	// sem limits the number of concurrent in-flight requests if it's not nil.
+	sem chan struct{}
+	// nonce generates the nonce for each request.
+	nonce NonceSource
+	// userAgents are the User-Agent headers used in turn for requests.
+	userAgents []string
+	// userAgentIndex is the index of the next User-Agent.
+	userAgentIndex uint32
+	// validator validates parsed responses if it's not nil.
+	validator func(*Response) error
+	// retry is the retry policy. Requests are not retried by default.
+	retry retryPolicy
+	// roundTripHook is invoked after each HTTP round trip if it's not nil.
+	roundTripHook func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+	// logger logs requests and responses at debug level if it's not nil.
+	logger *slog.Logger
+	// defaultSignName is the signature name of SendSMS() if it's empty.
+	defaultSignName string
+	// defaultTemplateCode is the template code of SendSMS() if it's empty.
+	defaultTemplateCode string
+	// clockSkew is the offset applied to generated timestamps. It's set by WithClockSkew().
+	clockSkew time.Duration
+	// clockSync is true if the time is synced with the server for expired timestamps.
+	clockSync bool
+	// serverTimeOffset is the offset in nanoseconds of the server time to the local time if it's synced.
+	serverTimeOffset int64
+	// inFlightRetries is the number of requests sleeping in the backoff of retries.
+	inFlightRetries int32
+	// done is closed by Close() to cancel the backoff of retries.
+	done chan struct{}
+	// closed is 1 after Close() is called.
+	closed uint32
+	// signatureFallback is true if the other signature method is tried when aliyun rejects the signature method.
+	signatureFallback bool
+	// signatureMethod is the signature method of requests if signatureFallback is true.
+	// It's the preferred method of WithSignatureFallback() until the other one is found working.
+	signatureMethod atomic.Value
 }
 
 // Response is the common response for aliyun message services APIs.
 type Response struct {
 	// RequestID is the request ID. e.g. "8906582E-6722".
-	RequestID string `json:"RequestId"` // Code is the status code. e.g. "OK", "SignatureDoesNotMatch".
-	Code      string `json:"Code"`      // Message is the detail message for the status code. e.g. "OK", Specified signature is not matched with our calculation...".
-	Message   string `json:"Message"`
-	// BizID is the business ID. It can be used to query the status of SMS. e.g. "134523^4351232".
+	RequestID string `json:"RequestId" xml:"RequestId"`
+	// Code is the status code. e.g. "OK", "SignatureDoesNotMatch".
+	Code string `json:"Code" xml:"Code"`
+	// Message is the detail message for the status code. e.g. "OK", Specified signature is not matched with our calculation...".
+	Message string `json:"Message" xml:"Message"`
+	// OutID is the caller's out ID echoed by aliyun if it's specified by OutID(). e.g. "123".
+	// It's empty if aliyun does not echo it.
+	OutID string `json:"OutId" xml:"OutId"`
+	// HTTPStatusCode is the status code of the HTTP response. e.g. 200, 400.
+	HTTPStatusCode int `json:"-" xml:"-"`
+	// AcsRequestID is the "X-Acs-Request-Id" header of the HTTP response.
+	// It's useful to correlate with aliyun support tickets when RequestID is empty. e.g. on 4xx errors.
+	AcsRequestID string `json:"-" xml:"-"`
+	// raw is the raw body of the HTTP response.
+	raw []byte
+}
+
+// Raw returns the raw body of the HTTP response.
+// It's useful to extract fields which are not modeled by this package yet.
+func (r *Response) Raw() []byte {
+	return r.raw
 }
 
 // SMSResponse is the response of HTTP request of sending SMS.
 type SMSResponse struct {
 	Response
-	BizID string `json:"BizId"`
+	// BizID is the business ID. It can be used to query the status of SMS. e.g. "134523^4351232".
+	BizID string `json:"BizId" xml:"BizId"`
 }
 
 // SingleCallByTTSResponse is the response of HTTP request of make single call by TTS.
 type SingleCallByTTSResponse struct {
 	Response
-	CallID string `json:"CallId"`
+	// CallID is the ID of the call. It can be used to query the status of the call. e.g. "116012354148^10281378".
+	CallID string `json:"CallId" xml:"CallId"`
+}
+
+// String formats the response for logs. e.g. "code=OK requestId=8906582E-6722 message=OK".
+// Only the listed fields are formatted to avoid leaking sensitive fields added in the future.
+func (r Response) String() string {
+	return fmt.Sprintf("code=%s requestId=%s message=%s", r.Code, r.RequestID, r.Message)
+}
+
+// String formats the response for logs. e.g. "code=OK requestId=8906582E-6722 bizId=134523^4351232 message=OK".
+func (r SMSResponse) String() string {
+	return fmt.Sprintf("code=%s requestId=%s bizId=%s message=%s", r.Code, r.RequestID, r.BizID, r.Message)
+}
+
+// String formats the response for logs. e.g. "code=OK requestId=8906582E-6722 callId=116012354148^10281378 message=OK".
+func (r SingleCallByTTSResponse) String() string {
+	return fmt.Sprintf("code=%s requestId=%s callId=%s message=%s", r.Code, r.RequestID, r.CallID, r.Message)
 }
 
 // NewClient creates a new client.
 //
 // It accepts 2 parameters: access key ID and secret.
 // Both of them are generated by user in aliyun control panel.
-func NewClient(accessKeyID, accessKeySecret string) *Client {
-	return &Client{
-		accessKeyID:     accessKeyID,
-		accessKeySecret: accessKeySecret,
+// options: optional options for the client. e.g. WithHTTP2().
+func NewClient(accessKeyID, accessKeySecret string, options ...Option) *Client {
+	return NewClientWithProvider(StaticCredentials(accessKeyID, accessKeySecret), options...)
+}
+
+// NewClientChecked creates a new client like NewClient() but fails fast on empty credentials.
+// It returns ErrEmptyAccessKeyID or ErrEmptyAccessKeySecret if the access key ID or secret is empty or whitespace-only,
+// instead of failing with "SignatureDoesNotMatch" of aliyun on requests.
+func NewClientChecked(accessKeyID, accessKeySecret string, options ...Option) (*Client, error) {
+	if strings.TrimSpace(accessKeyID) == "" {
+		return nil, ErrEmptyAccessKeyID
 	}
+	if strings.TrimSpace(accessKeySecret) == "" {
+		return nil, ErrEmptyAccessKeySecret
+	}
+	return NewClient(accessKeyID, accessKeySecret, options...), nil
+}
+
+// NewClientWithProvider creates a new client which fetches the credentials by the provider for each request.
+// e.g. rotate access keys via RAM roles.
+//
+// options: optional options for the client. e.g. WithHTTP2().
+func NewClientWithProvider(provider CredentialProvider, options ...Option) *Client {
+	c := &Client{
+		credentials: provider,
+		nonce:       UUIDNonceSource,
+		done:        make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Close cancels the backoff sleeps of retries immediately, so the retried requests return ErrClientClosed.
+// Later retries are not made either. Requests which are not retried are still sent.
+// It also closes idle connections of the transport. It's safe to call it more than once.
+func (c *Client) Close() error {
+	if atomic.CompareAndSwapUint32(&c.closed, 0, 1) && c.done != nil {
+		close(c.done)
+	}
+	c.CloseIdleConnections()
+	return nil
+}
+
+// InFlightRetries returns the number of requests sleeping in the backoff of retries.
+// e.g. observe the requests to wait for in graceful shutdown. See WithRetry() and Close().
+func (c *Client) InFlightRetries() int {
+	return int(atomic.LoadInt32(&c.inFlightRetries))
+}
+
+// String formats the client for logs. e.g. "message.Client{accessKeyID=my_key_id accessKeySecret=my****et}".
+// The access key secret is masked. The internals of the embedded http.Client are omitted.
+// Both are "(provider)" if the client is created with a CredentialProvider other than StaticCredentials.
+func (c *Client) String() string {
+	accessKeyID, accessKeySecret := "(provider)", "(provider)"
+	if s, ok := c.credentials.(staticCredentials); ok {
+		accessKeyID = s.id
+		accessKeySecret = maskSecret(s.secret)
+	}
+	return fmt.Sprintf("message.Client{accessKeyID=%s accessKeySecret=%s}", accessKeyID, accessKeySecret)
+}
+
+// DebugConfig returns the effective configuration of the client for debugging.
+// The access key secret is never included. The user info of the proxy URL is redacted.
+// The access key ID is "(provider)" if the client is created with a CredentialProvider other than StaticCredentials.
+//
+// The region and the scheme are the ones used by requests without RegionID() or Scheme().
+// The endpoints are resolved by Endpoints for the region, so overridden entries are reported.
+func (c *Client) DebugConfig() string {
+	accessKeyID := "(provider)"
+	if s, ok := c.credentials.(staticCredentials); ok {
+		accessKeyID = s.id
+	}
+
+	smsEndpoint, err := EndpointForService(DefaultRegionID, ServiceSMS)
+	if err != nil {
+		smsEndpoint = "(" + err.Error() + ")"
+	}
+	voiceEndpoint, err := EndpointForService(DefaultRegionID, ServiceVoice)
+	if err != nil {
+		voiceEndpoint = "(" + err.Error() + ")"
+	}
+
+	signatureMethod := pop.HMACSHA1
+	if c.signatureFallback {
+		signatureMethod = c.signatureMethod.Load().(string)
+	}
+
+	return fmt.Sprintf("accessKeyID=%s regionID=%s scheme=%s smsEndpoint=%s smsVersion=%s voiceEndpoint=%s voiceVersion=%s proxy=%s userAgent=%s timeout=%v maxConcurrency=%d maxAttempts=%d retryBaseDelay=%v defaultSignName=%q defaultTemplateCode=%q clockSkew=%v clockSync=%v signatureMethod=%s signatureFallback=%v",
+		accessKeyID,
+		DefaultRegionID,
+		DefaultScheme,
+		smsEndpoint,
+		smsVersion,
+		voiceEndpoint,
+		voiceVersion,
+		c.proxy(smsEndpoint),
+		c.userAgent(),
+		c.Timeout,
+		cap(c.sem),
+		c.retry.attempts(),
+		c.retry.baseDelay,
+		c.defaultSignName,
+		c.defaultTemplateCode,
+		c.clockSkew,
+		c.clockSync,
+		signatureMethod,
+		c.signatureFallback,
+	)
+}
+
+// proxy returns the redacted proxy URL of the transport for requests to the host.
+// It's "(none)" if no proxy is used and "(custom transport)" if the transport is not a *http.Transport.
+func (c *Client) proxy(host string) string {
+	var t *http.Transport
+	switch rt := c.Transport.(type) {
+	case nil:
+		t, _ = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		t = rt
+	}
+	if t == nil {
+		return "(custom transport)"
+	}
+	if t.Proxy == nil {
+		return "(none)"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, DefaultScheme+"://"+host+"/", nil)
+	if err != nil {
+		return "(none)"
+	}
+	u, err := t.Proxy(req)
+	if err != nil {
+		return "(" + err.Error() + ")"
+	}
+	if u == nil {
+		return "(none)"
+	}
+	return u.Redacted()
 }
 
 // SpecialURLEncode follows aliyun's POP protocol to do special URL encoding.
 func SpecialURLEncode(str string) string {
-	encodedStr := url.QueryEscape(str)
-	encodedStr = strings.Replace(encodedStr, "+", "%20", -1)
-	encodedStr = strings.Replace(encodedStr, "*", "%2A", -1)
-	encodedStr = strings.Replace(encodedStr, "%7E", "~", -1)
-	return encodedStr
+	return pop.SpecialURLEncode(str)
 }
 
 // SetDefaultCommonParams sets the default common parameters for aliyun services.
+// The access key ID is set only if the client is created with static credentials.
+// Otherwise it's set by the credentials fetched for each request.
 func (c *Client) SetDefaultCommonParams(v url.Values) {
 	// Set access key ID.
-	v.Set("AccessKeyId", c.accessKeyID)
+	if s, ok := c.credentials.(staticCredentials); ok {
+		v.Set("AccessKeyId", s.id)
+	}
 
 	// Set default common parameters
-	v.Set("Timestamp", GenTimestamp(time.Now()))
+	v.Set("Timestamp", GenTimestamp(c.now()))
 	v.Set("Format", "JSON")
 	v.Set("SignatureMethod", "HMAC-SHA1")
 	v.Set("SignatureVersion", "1.0")
-	UUID, _ := uuid.New()
-	v.Set("SignatureNonce", UUID)
+	v.Set("SignatureNonce", c.nonce())
 }
 
 // SignedString follow aliyun's POP protocol to generate the signature.
 // httpMethod: follow aliyun doc. e.g. "GET" for sending SMS and single TTS call.
+// sortedQueryStr: canonicalized query string whose keys and values are encoded by SpecialURLEncode.
+// See Sign() to get it from parameters.
+// It signs with the "SignatureMethod" in the query: "HMAC-SHA1"(default) or "HMAC-SHA256".
+// It returns an empty string for other signature methods. Use SignedStringChecked() to get the error.
 func (c *Client) SignedString(httpMethod, sortedQueryStr string) string {
-	str := httpMethod + "&" + url.QueryEscape("/") + "&" + SpecialURLEncode(sortedQueryStr)
+	sign, _ := c.SignedStringChecked(httpMethod, sortedQueryStr)
+	return sign
+}
+
+// SignedStringChecked generates the signature like SignedString() but returns an error for unsupported signature methods.
+func (c *Client) SignedStringChecked(httpMethod, sortedQueryStr string) (string, error) {
+	return signString(c.secret(), httpMethod, querySignatureMethod(sortedQueryStr), sortedQueryStr)
+}
+
+// Base64Signature returns the base64 encoded signature before URL encoding for inspection.
+// SignedString() returns the URL encoded form of it which is sent to aliyun.
+// It returns an empty string for unsupported signature methods. Use Base64SignatureChecked() to get the error.
+func (c *Client) Base64Signature(httpMethod, sortedQueryStr string) string {
+	sign, _ := c.Base64SignatureChecked(httpMethod, sortedQueryStr)
+	return sign
+}
+
+// Base64SignatureChecked returns the signature like Base64Signature() but returns an error for unsupported signature methods.
+func (c *Client) Base64SignatureChecked(httpMethod, sortedQueryStr string) (string, error) {
+	return base64Signature(c.secret(), httpMethod, querySignatureMethod(sortedQueryStr), sortedQueryStr)
+}
+
+// secret returns the access key secret for SignedString() and Base64Signature().
+// The credentials are fetched with the background context. It's empty if it fails.
+func (c *Client) secret() string {
+	if c.credentials == nil {
+		return ""
+	}
+	_, secret, _, _ := c.credentials.Credentials(context.Background())
+	return secret
+}
+
+// Sign follows aliyun's POP protocol to sign the parameters of any GET request with the access key secret.
+// It's decoupled from Client and can be used to call aliyun actions which are not wrapped by this package.
+//
+// params: all parameters of the request except "Signature".
+// e.g. AccessKeyId, Action, Version, Timestamp, SignatureNonce...
+//
+// Parameters with empty values are present as "key=" in the canonicalized query string
+// as aliyun requires.
+//
+// It returns the URL encoded signature and the canonicalized query string.
+// The final query string is "Signature=" + signature + "&" + canonicalString.
+// The signature is empty if "SignatureMethod" is not "HMAC-SHA1"(default) or "HMAC-SHA256". Use SignChecked() to get the error.
+func Sign(params map[string]string, secret string) (signature, canonicalString string) {
+	signature, canonicalString, _ = SignChecked(params, secret)
+	return signature, canonicalString
+}
+
+// SignChecked signs the parameters like Sign() but returns an error for unsupported signature methods.
+// The canonicalized query string is returned even if there's an error.
+func SignChecked(params map[string]string, secret string) (signature, canonicalString string, err error) {
+	v := url.Values{}
+	for key, value := range params {
+		v.Set(key, value)
+	}
+
+	canonicalString = canonicalQuery(v)
+	signature, err = signString(secret, "GET", v.Get("SignatureMethod"), canonicalString)
+	return signature, canonicalString, err
+}
+
+// CanonicalizedQuery returns the canonicalized query string of the parameters which is signed.
+// Parameters are sorted by keys. Both keys and values are encoded by SpecialURLEncode.
+// It's useful to debug "SignatureDoesNotMatch" errors. e.g. special characters in template params.
+func CanonicalizedQuery(v url.Values) string {
+	return pop.CanonicalQuery(v)
+}
+
+// StringToSign returns the string to sign of the HTTP method and the canonicalized query string.
+// e.g. "GET&%2F&" + SpecialURLEncode(sortedQueryStr).
+// Log and diff it with the expected one in the message of "SignatureDoesNotMatch" errors.
+// SignedString() signs it.
+func StringToSign(httpMethod, sortedQueryStr string) string {
+	return pop.StringToSign(httpMethod, sortedQueryStr)
+}
+
+// Signature signs the parameters of the request with the HTTP method and the access key secret.
+// It's useful to sign custom actions which are not wrapped by this package. See also Sign().
+//
+// httpMethod: "GET" or "POST".
+// v: all parameters of the request except "Signature".
+//
+// It signs with the "SignatureMethod" in the parameters: "HMAC-SHA1"(default) or "HMAC-SHA256".
+// It returns the URL encoded signature. It's empty for other signature methods. Use SignatureChecked() to get the error.
+// The final query string is "Signature=" + signature + "&" + CanonicalizedQuery(v).
+func Signature(httpMethod string, v url.Values, secret string) string {
+	sign, _ := SignatureChecked(httpMethod, v, secret)
+	return sign
+}
 
-	// HMAC-SHA1
-	// aliyun requires appending "&" after access key secret.
-	mac := hmac.New(sha1.New, []byte(c.accessKeySecret+"&"))
-	mac.Write([]byte(str))
+// SignatureChecked signs the parameters like Signature() but returns an error for unsupported signature methods.
+func SignatureChecked(httpMethod string, v url.Values, secret string) (string, error) {
+	return signString(secret, httpMethod, v.Get("SignatureMethod"), CanonicalizedQuery(v))
+}
 
-	sign := base64.StdEncoding.EncodeToString(mac.Sum(nil))
-	return SpecialURLEncode(sign)
+// canonicalQuery returns the canonicalized query string of the parameters.
+func canonicalQuery(v url.Values) string {
+	return CanonicalizedQuery(v)
+}
+
+// signString generates the URL encoded signature of the HTTP method and the sorted query string.
+// signatureMethod: "HMAC-SHA1" or "HMAC-SHA256". It's "HMAC-SHA1" if it's empty.
+// It returns an error for unsupported signature methods.
+func signString(secret, httpMethod, signatureMethod, sortedQueryStr string) (string, error) {
+	sign, err := base64Signature(secret, httpMethod, signatureMethod, sortedQueryStr)
+	if err != nil {
+		return "", err
+	}
+	return SpecialURLEncode(sign), nil
+}
+
+// base64Signature generates the base64 encoded signature of the HTTP method and the sorted query string.
+// See signString() for the signature method.
+func base64Signature(secret, httpMethod, signatureMethod, sortedQueryStr string) (string, error) {
+	return pop.Sign(secret, httpMethod, sortedQueryStr, signatureMethod)
+}
+
+// querySignatureMethod returns the "SignatureMethod" in the sorted query string.
+func querySignatureMethod(sortedQueryStr string) string {
+	v, _ := url.ParseQuery(sortedQueryStr)
+	return v.Get("SignatureMethod")
+}
+
+// Prewarm opens a connection to the SMS service endpoint ahead of time and keeps it in the pool,
+// so the first real request reuses it. e.g. for the latency-critical first OTP after deploy.
+//
+// params: optional parameters to resolve the endpoint the same way as SendSMS(). e.g. RegionID(), Endpoint(), Scheme().
+// Pass the same ones as the later requests. Otherwise the warmed connection is not reused by them.
+//
+// It sends an unsigned HEAD request which is not an API call. The cost is one extra round trip and
+// an idle connection which may be closed by the transport or aliyun after the idle timeout.
+func (c *Client) Prewarm(ctx context.Context, params ...Param) error {
+	v := url.Values{}
+	v.Set("RegionId", DefaultRegionID)
+	o, err := applyParams(v, params)
+	if err != nil {
+		return err
+	}
+
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
+		return err
+	}
+
+	scheme := o.scheme
+	if scheme == "" {
+		scheme = DefaultScheme
+	}
+
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   host,
+		Path:   "/",
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	// Drain and close the body to return the connection to the pool.
+	io.Copy(ioutil.Discard, resp.Body)
+	return resp.Body.Close()
 }
 
 // SendSMS sends the SMS to phone numbers.
 //
 // phoneNumbers: one or more phone numbers. aliyun recommends to send SMS to only one phone number once for validation code.
 // signName: permitted signature name. You may apply one ore more signature names in aliyun's control panel.
+// Pass "" to use the default one specified by WithDefaultSignName().
 // templateCode: permitted template code. You may apply one or more template code in aliyun's control panel.
+// Pass "" to use the default one specified by WithDefaultTemplateCode().
 // templateParam: JSON to render the template. e.g. {"code":"1234","product":"ytx"}.
 // params: optional parameters for sending SMS. In most case, no need to pass params.
 // You may also specify params by helper functions. e.g. Timestamp(), SignatureNonce().
 //
+// Parameters are set in order: default common parameters, the arguments(or defaults of the client for empty ones) and params.
+// So params always override others. e.g. PhoneNumbers() and SignName() override phoneNumbers and signName.
+//
 // It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+// It's ErrNoPhoneNumbers if there's no phone number or all of them are blank.
+// At most MaxPhoneNumbersPerSend phone numbers can be sent in one request.
 //
 // For example:
 //
@@ -112,14 +517,29 @@ func (c *Client) SignedString(httpMethod, sortedQueryStr string) string {
 //
 // ok, resp, err := c.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234","product":"ytx"}`)
 func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, error) {
+	return c.SendSMSContext(context.Background(), phoneNumbers, signName, templateCode, templateParam, params...)
+}
+
+// SendSMSContext sends the SMS to phone numbers with the context.
+// The HTTP request is aborted when the context is canceled or its deadline exceeds.
+// See SendSMS() for other parameters.
+func (c *Client) SendSMSContext(ctx context.Context, phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, error) {
 	v := url.Values{}
 	// Set default common parameters for aliyun services.
 	c.SetDefaultCommonParams(v)
 
 	// Set default business parameters for sending SMS.
 	v.Set("Action", "SendSms")
-	v.Set("Version", "2017-05-25")
-	v.Set("RegionId", "cn-hangzhou")
+	v.Set("Version", smsVersion)
+	v.Set("RegionId", DefaultRegionID)
+
+	// Fall back to the defaults of the client.
+	if signName == "" {
+		signName = c.defaultSignName
+	}
+	if templateCode == "" {
+		templateCode = c.defaultTemplateCode
+	}
 
 	// Set required business parameters
 	v.Set("PhoneNumbers", GenPhoneNumbersStr(phoneNumbers))
@@ -128,54 +548,32 @@ func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, template
 	v.Set("TemplateParam", templateParam)
 
 	// Override parameters if need.
-	for _, param := range params {
-		param.f(v)
-	}
-
-	// Get sorted query string by keys.
-	sortedQueryStr := v.Encode()
-
-	// Get signature.
-	sign := c.SignedString("GET", sortedQueryStr)
-
-	// Make final query string with signature.
-	rawQuery := fmt.Sprintf("Signature=%s&%s", sign, sortedQueryStr)
-
-	// New a URL with host, raw query.
-	u := &url.URL{
-		Scheme:   "http",
-		Host:     "dysmsapi.aliyuncs.com",
-		Path:     "/",
-		RawQuery: rawQuery,
-	}
-
-	req, err := http.NewRequest("GET", u.String(), nil)
+	// Params are applied last, so they override both the defaults and the arguments.
+	o, err := applyParams(v, params)
 	if err != nil {
 		return false, nil, err
 	}
+	ignoreVoiceParams(v)
 
-	resp, err := c.Do(req)
-	if err != nil {
-		return false, nil, err
+	// Check the phone numbers after they're overridden by params.
+	phoneNumbersStr := v.Get("PhoneNumbers")
+	if phoneNumbersStr == "" {
+		return false, nil, ErrNoPhoneNumbers
 	}
-	defer resp.Body.Close()
-
-	buf, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return false, nil, err
+	if n := phoneCount(phoneNumbersStr); n > MaxPhoneNumbersPerSend {
+		return false, nil, fmt.Errorf("too many phone numbers: %d, max: %d, split them by ChunkPhoneNumbers() and send by SendMany() or SendBatchSMS()", n, MaxPhoneNumbersPerSend)
 	}
 
-	// Parse JSON response
 	response := &SMSResponse{}
-	if err = json.Unmarshal(buf, response); err != nil {
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
 		return false, nil, err
 	}
-
-	if strings.ToUpper(response.Code) != "OK" {
-		return false, response, nil
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
 	}
-	return true, response, nil
-
+	return result.ok, response, err
 }
 
 // MakeSingleCallByTTS makes the single call by TTS.
@@ -188,6 +586,7 @@ func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, template
 // You may also specify params by helper functions. e.g. Timestamp(), SignatureNonce().
 //
 // It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
 //
 // For example:
 //
@@ -195,14 +594,21 @@ func (c *Client) SendSMS(phoneNumbers []string, signName, templateCode, template
 //
 // ok, resp, err := c.MakeSingleCallByTTS("02560000000", "1500000000", "TTS_0000", `{"code":"1234","product":"ytx"}`)
 func (c *Client) MakeSingleCallByTTS(calledShowNumber, calledNumber, ttsCode, ttsParam string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
+	return c.MakeSingleCallByTTSContext(context.Background(), calledShowNumber, calledNumber, ttsCode, ttsParam, params...)
+}
+
+// MakeSingleCallByTTSContext makes the single call by TTS with the context.
+// The HTTP request is aborted when the context is canceled or its deadline exceeds.
+// See MakeSingleCallByTTS() for other parameters.
+func (c *Client) MakeSingleCallByTTSContext(ctx context.Context, calledShowNumber, calledNumber, ttsCode, ttsParam string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
 	v := url.Values{}
 	// Set default common parameters for aliyun services.
 	c.SetDefaultCommonParams(v)
 
 	// Set default business parameters for sending SMS.
 	v.Set("Action", "SingleCallByTts")
-	v.Set("Version", "2017-05-25")
-	v.Set("RegionId", "cn-hangzhou")
+	v.Set("Version", voiceVersion)
+	v.Set("RegionId", DefaultRegionID)
 
 	// Set required business parameters
 	v.Set("CalledShowNumber", calledShowNumber)
@@ -211,51 +617,217 @@ func (c *Client) MakeSingleCallByTTS(calledShowNumber, calledNumber, ttsCode, tt
 	v.Set("TtsParam", ttsParam)
 
 	// Override parameters if need.
-	for _, param := range params {
-		param.f(v)
+	o, err := applyParams(v, params)
+	if err != nil {
+		return false, nil, err
+	}
+
+	response := &SingleCallByTTSResponse{}
+	host, err := serviceHost(v, o, ServiceVoice)
+	if err != nil {
+		return false, nil, err
+	}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// userAgent returns the User-Agent header for DebugConfig().
+// The User-Agent headers are joined by "," if they're used in turn.
+func (c *Client) userAgent() string {
+	if len(c.userAgents) == 0 {
+		return DefaultUserAgent
+	}
+	return strings.Join(c.userAgents, ",")
+}
+
+// hook invokes the round trip hook if it's set.
+func (c *Client) hook(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	if c.roundTripHook != nil {
+		c.roundTripHook(req, resp, err, elapsed)
+	}
+}
+
+// checkResponse validates the parsed response by the validator of the client
+// and reports if the status code is "OK".
+// It returns an *APIError if the status code is not "OK".
+func (c *Client) checkResponse(r *Response) (bool, error) {
+	if c.validator != nil {
+		if err := c.validator(r); err != nil {
+			return false, err
+		}
+	}
+
+	if !r.IsSuccess() {
+		return false, newAPIError(r)
+	}
+	return true, nil
+}
+
+// newRequest fetches the credentials, signs the parameters and returns the HTTP request to the host.
+// It's not sent.
+func (c *Client) newRequest(ctx context.Context, host string, v url.Values, o *requestOptions) (*http.Request, error) {
+	// Fetch the credentials for each request.
+	if c.credentials == nil {
+		return nil, ErrNoCredentialProvider
+	}
+	id, secret, securityToken, err := c.credentials.Credentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.Set("AccessKeyId", id)
+	if securityToken != "" {
+		v.Set("SecurityToken", securityToken)
 	}
 
-	// Get sorted query string by keys.
-	sortedQueryStr := v.Encode()
+	// Get canonicalized query string sorted by keys.
+	// url.Values.Encode() can not be used because it encodes " " to "+" but aliyun requires "%20".
+	sortedQueryStr := canonicalQuery(v)
 
-	// Get signature.
-	sign := c.SignedString("GET", sortedQueryStr)
+	method := o.method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// Get signature with the declared signature method.
+	sign, err := signString(secret, method, v.Get("SignatureMethod"), sortedQueryStr)
+	if err != nil {
+		return nil, err
+	}
+	c.logRequest(ctx, v, sign)
 
 	// Make final query string with signature.
 	rawQuery := fmt.Sprintf("Signature=%s&%s", sign, sortedQueryStr)
 
-	// New a URL with host, raw query.
+	// Send the parameters in the body for POST.
+	var body io.Reader
+	if method == http.MethodPost {
+		body = strings.NewReader(rawQuery)
+		rawQuery = ""
+	}
+
+	scheme := o.scheme
+	if scheme == "" {
+		scheme = DefaultScheme
+	}
+
+	// Override the host by the explicit endpoint.
+	if o.endpoint != "" {
+		host = o.endpoint
+	}
+
+	// New a URL with scheme, host, raw query.
+	// The scheme is not signed, so the signature is the same for "http" and "https".
 	u := &url.URL{
-		Scheme:   "http",
-		Host:     "dyvmsapi.aliyuncs.com",
+		Scheme:   scheme,
+		Host:     host,
 		Path:     "/",
 		RawQuery: rawQuery,
 	}
 
-	req, err := http.NewRequest("GET", u.String(), nil)
+	// Carry the local options on the request context.
+	if o.clientRequestID != "" {
+		ctx = context.WithValue(ctx, clientRequestIDKey{}, o.clientRequestID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
 	if err != nil {
-		return false, nil, err
+		return nil, err
 	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	userAgent := DefaultUserAgent
+	if n := len(c.userAgents); n > 0 {
+		i := atomic.AddUint32(&c.userAgentIndex, 1) - 1
+		userAgent = c.userAgents[i%uint32(n)]
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	// Accept gzip encoded bodies. They're decompressed by readBody().
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	// Negotiate the content type with the format.
+	if isXMLFormat(v) {
+		req.Header.Set("Accept", "application/xml")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+	return req, nil
+}
+
+// readBody reads the body of the HTTP response.
+// It's decompressed if the "Content-Encoding" is gzip.
+func readBody(resp *http.Response) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	r, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// The body is decompressed.
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return ioutil.ReadAll(r)
+}
 
+// isXMLFormat reports if the response format specified by "Format" parameter is XML.
+func isXMLFormat(v url.Values) bool {
+	return strings.ToUpper(v.Get("Format")) == "XML"
+}
+
+// do signs the parameters, makes the HTTP request to the host
+// and parses the response in the format specified by "Format" parameter.
+// It returns the HTTP response whose body is consumed and the body if it's received and error.
+func (c *Client) do(ctx context.Context, host string, v url.Values, o *requestOptions, response interface{}) (*http.Response, []byte, error) {
+	req, err := c.newRequest(ctx, host, v, o)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Wait for a free slot if the concurrency is limited.
+	if c.sem != nil {
+		select {
+		case c.sem <- struct{}{}:
+			defer func() { <-c.sem }()
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	start := time.Now()
 	resp, err := c.Do(req)
 	if err != nil {
-		return false, nil, err
+		c.hook(req, nil, err, time.Since(start))
+		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
-	buf, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return false, nil, err
+	// Record the signed URL only after the request is sent.
+	if o.signedURL != nil {
+		*o.signedURL = signedURL(req)
 	}
 
-	// Parse JSON response
-	response := &SingleCallByTTSResponse{}
-	if err = json.Unmarshal(buf, response); err != nil {
-		return false, nil, err
+	buf, err := readBody(resp)
+	// Make the body readable for the hook.
+	resp.Body = ioutil.NopCloser(bytes.NewReader(buf))
+	c.hook(req, resp, err, time.Since(start))
+	if err != nil {
+		return resp, nil, err
 	}
 
-	if strings.ToUpper(response.Code) != "OK" {
-		return false, response, nil
+	// Parse XML or JSON response.
+	if isXMLFormat(v) {
+		return resp, buf, xml.Unmarshal(buf, response)
 	}
-	return true, response, nil
+	return resp, buf, json.Unmarshal(buf, response)
 }
@@ -0,0 +1,37 @@
+package message
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// now returns the current time to generate timestamps.
+// It's adjusted by the server time offset if it's synced, or by the offset of WithClockSkew() otherwise.
+func (c *Client) now() time.Time {
+	if offset := atomic.LoadInt64(&c.serverTimeOffset); offset != 0 {
+		return time.Now().Add(time.Duration(offset))
+	}
+	return time.Now().Add(c.clockSkew)
+}
+
+// syncServerTime syncs the time with the "Date" header of the response if it's enabled by WithClockSkew().
+// It reports whether the time is synced.
+func (c *Client) syncServerTime(resp *http.Response) bool {
+	if !c.clockSync || resp == nil {
+		return false
+	}
+
+	t, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return false
+	}
+
+	offset := time.Until(t)
+	if offset == 0 {
+		// 0 means not synced. Use the smallest offset instead.
+		offset = time.Nanosecond
+	}
+	atomic.StoreInt64(&c.serverTimeOffset, int64(offset))
+	return true
+}
@@ -0,0 +1,140 @@
+package message_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+const expiredBody = `{"RequestId":"STUB-EXPIRED","Code":"InvalidTimeStamp.Expired","Message":"Specified time stamp or date value is expired."}`
+
+// sentTimestamp returns the parsed timestamp of the request.
+func sentTimestamp(t *testing.T, req *http.Request) time.Time {
+	params, _ := sentParams(req)
+	ts, err := time.Parse("2006-01-02T15:04:05Z", params["Timestamp"])
+	if err != nil {
+		t.Fatalf("parse timestamp %q error: %v", params["Timestamp"], err)
+	}
+	return ts
+}
+
+// near reports whether t is within 5 seconds of want.
+func near(t, want time.Time) bool {
+	d := t.Sub(want)
+	return d > -5*time.Second && d < 5*time.Second
+}
+
+func TestWithClockSkew(t *testing.T) {
+	var req *http.Request
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithClockSkew(-2*time.Hour))
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want OK", ok, err)
+	}
+	if ts, want := sentTimestamp(t, req), time.Now().Add(-2*time.Hour); !near(ts, want) {
+		t.Errorf("Timestamp = %v, want about %v", ts, want)
+	}
+}
+
+func TestClockSkewSyncServerTime(t *testing.T) {
+	serverTime := time.Now().Add(30 * time.Minute).UTC()
+	fixed, _ := time.Parse(time.RFC3339, "2020-01-01T00:00:00Z")
+
+	tests := []struct {
+		options []message.Option
+		params  []message.Param
+		// date is the "Date" header of the expired response.
+		date   string
+		bodies []string
+		ok     bool
+		// requests is the expected number of requests.
+		requests int
+	}{
+		// The expired timestamp is retried once with the server time.
+		{[]message.Option{message.WithClockSkew(0)}, nil, serverTime.Format(http.TimeFormat), []string{expiredBody, `{"Code":"OK"}`}, true, 2},
+		// The retry with the server time is only once.
+		{[]message.Option{message.WithClockSkew(0)}, nil, serverTime.Format(http.TimeFormat), []string{expiredBody, expiredBody}, false, 2},
+		// Not retried without the server time.
+		{[]message.Option{message.WithClockSkew(0)}, nil, "", []string{expiredBody}, false, 1},
+		// Not retried without WithClockSkew().
+		{nil, nil, serverTime.Format(http.TimeFormat), []string{expiredBody}, false, 1},
+		// Not retried for the timestamp specified by Timestamp().
+		{[]message.Option{message.WithClockSkew(0)}, []message.Param{message.Timestamp(fixed)}, serverTime.Format(http.TimeFormat), []string{expiredBody}, false, 1},
+	}
+
+	for _, tt := range tests {
+		requests := []*http.Request{}
+		client := message.NewClient("my_key_id", "my_key_secret", tt.options...)
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			i := len(requests)
+			requests = append(requests, req)
+			resp := newStubResponse(http.StatusBadRequest, tt.bodies[i])
+			if tt.bodies[i] == `{"Code":"OK"}` {
+				resp.StatusCode = http.StatusOK
+			}
+			if tt.date != "" {
+				resp.Header.Set("Date", tt.date)
+			}
+			return resp, nil
+		})
+
+		ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, tt.params...)
+		if ok != tt.ok || (err == nil) != tt.ok {
+			t.Errorf("SendSMS() = %v, %v, want ok: %v", ok, err, tt.ok)
+		}
+		if len(requests) != tt.requests {
+			t.Fatalf("requests = %v, want %v", len(requests), tt.requests)
+		}
+		if len(tt.params) > 0 {
+			// The specified timestamp is kept.
+			if ts := sentTimestamp(t, requests[0]); !ts.Equal(fixed) {
+				t.Errorf("Timestamp = %v, want %v", ts, fixed)
+			}
+		}
+
+		if tt.requests > 1 {
+			// The retry uses the server time and a regenerated nonce.
+			if ts := sentTimestamp(t, requests[1]); !near(ts, serverTime) {
+				t.Errorf("Timestamp of the retry = %v, want about %v", ts, serverTime)
+			}
+			first, _ := sentParams(requests[0])
+			second, _ := sentParams(requests[1])
+			if first["SignatureNonce"] == second["SignatureNonce"] {
+				t.Errorf("SignatureNonce of the retry is not regenerated")
+			}
+		}
+	}
+}
+
+func TestClockSkewServerTimeKept(t *testing.T) {
+	serverTime := time.Now().Add(-time.Hour).UTC()
+
+	requests := []*http.Request{}
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithClockSkew(time.Minute))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		if len(requests) == 1 {
+			resp := newStubResponse(http.StatusBadRequest, expiredBody)
+			resp.Header.Set("Date", serverTime.Format(http.TimeFormat))
+			return resp, nil
+		}
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+			t.Fatalf("SendSMS() = %v, %v, want OK", ok, err)
+		}
+	}
+
+	// The server time offset replaces the specified offset for later requests.
+	if ts := sentTimestamp(t, requests[2]); !near(ts, serverTime) {
+		t.Errorf("Timestamp of the later request = %v, want about %v", ts, serverTime)
+	}
+}
@@ -0,0 +1,81 @@
+package message
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Status codes of the response.
+const (
+	// CodeOK is the status code of a successful request.
+	CodeOK = "OK"
+)
+
+// Status codes of requests denied by aliyun's flow control.
+const (
+	CodeBusinessLimitControl = "isv.BUSINESS_LIMIT_CONTROL"
+	CodeThrottling           = "Throttling"
+	CodeThrottlingUser       = "Throttling.User"
+	CodeThrottlingAPI        = "Throttling.Api"
+)
+
+// Status codes of requests whose signature or credentials are invalid.
+const (
+	CodeSignatureDoesNotMatch      = "SignatureDoesNotMatch"
+	CodeSignatureNonceUsed         = "SignatureNonceUsed"
+	CodeInvalidTimeStampExpired    = "InvalidTimeStamp.Expired"
+	CodeInvalidAccessKeyIDNotFound = "InvalidAccessKeyId.NotFound"
+	CodeInvalidSignatureMethod     = "InvalidSignatureMethod"
+)
+
+// Status codes of requests whose parameters are invalid.
+const (
+	CodeMobileNumberIllegal        = "isv.MOBILE_NUMBER_ILLEGAL"
+	CodeMobileCountOverLimit       = "isv.MOBILE_COUNT_OVER_LIMIT"
+	CodeSMSSignatureIllegal        = "isv.SMS_SIGNATURE_ILLEGAL"
+	CodeSMSSignIllegal             = "isv.SMS_SIGN_ILLEGAL"
+	CodeSMSTemplateIllegal         = "isv.SMS_TEMPLATE_ILLEGAL"
+	CodeTemplateMissingParameters  = "isv.TEMPLATE_MISSING_PARAMETERS"
+	CodeInvalidParameters          = "isv.INVALID_PARAMETERS"
+	CodeInvalidJSONParam           = "isv.INVALID_JSON_PARAM"
+	CodeParamLengthLimit           = "isv.PARAM_LENGTH_LIMIT"
+	CodeBlackKeyControlLimit       = "isv.BLACK_KEY_CONTROL_LIMIT"
+	CodeDayLimitControl            = "isv.DAY_LIMIT_CONTROL"
+	CodeSMSContentIllegal          = "isv.SMS_CONTENT_ILLEGAL"
+	CodeExtendCodeError            = "isv.EXTEND_CODE_ERROR"
+	CodeDomesticNumberNotSupported = "isv.DOMESTIC_NUMBER_NOT_SUPPORTED"
+)
+
+// Status codes of requests denied by the account or the balance.
+const (
+	CodeAmountNotEnough = "isv.AMOUNT_NOT_ENOUGH"
+	CodeOutOfService    = "isv.OUT_OF_SERVICE"
+	CodeAccountNotExist = "isv.ACCOUNT_NOT_EXISTS"
+	CodeAccountAbnormal = "isv.ACCOUNT_ABNORMAL"
+)
+
+// throttlingCodes are status codes of requests denied by aliyun's flow control.
+var throttlingCodes = map[string]bool{
+	CodeBusinessLimitControl: true,
+	CodeThrottling:           true,
+	CodeThrottlingUser:       true,
+	CodeThrottlingAPI:        true,
+}
+
+// remediationErrors map status codes to the errors which tell callers how to fix the request.
+var remediationErrors = map[string]error{
+	CodeSMSSignatureIllegal: ErrSignIllegal,
+	CodeSMSSignIllegal:      ErrSignIllegal,
+	CodeSMSTemplateIllegal:  ErrTemplateIllegal,
+}
+
+// IsSuccess reports whether the status code is "OK".
+func (r *Response) IsSuccess() bool {
+	return strings.ToUpper(r.Code) == CodeOK
+}
+
+// Retryable reports whether the request should be retried.
+// It's true if the request is denied by aliyun's flow control or failed by server errors(HTTP 5xx).
+func (r *Response) Retryable() bool {
+	return throttlingCodes[r.Code] || r.HTTPStatusCode >= http.StatusInternalServerError
+}
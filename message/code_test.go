@@ -0,0 +1,36 @@
+package message_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestResponseClassification(t *testing.T) {
+	tests := []struct {
+		code           string
+		httpStatusCode int
+		success        bool
+		retryable      bool
+	}{
+		{message.CodeOK, http.StatusOK, true, false},
+		{"ok", http.StatusOK, true, false},
+		{message.CodeBusinessLimitControl, http.StatusOK, false, true},
+		{message.CodeThrottlingUser, http.StatusBadRequest, false, true},
+		{"InternalError", http.StatusInternalServerError, false, true},
+		{message.CodeSignatureDoesNotMatch, http.StatusBadRequest, false, false},
+		{message.CodeMobileNumberIllegal, http.StatusOK, false, false},
+		{message.CodeAmountNotEnough, http.StatusOK, false, false},
+	}
+
+	for _, tt := range tests {
+		r := &message.Response{Code: tt.code, HTTPStatusCode: tt.httpStatusCode}
+		if got := r.IsSuccess(); got != tt.success {
+			t.Errorf("IsSuccess() of %v = %v, want %v", tt.code, got, tt.success)
+		}
+		if got := r.Retryable(); got != tt.retryable {
+			t.Errorf("Retryable() of %v = %v, want %v", tt.code, got, tt.retryable)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package config provides the configuration shape used by aliyun message services examples and tests.
+//
+// It matches message/config.example.json:
+//
+//	{
+//	    "access_key_id":"test_key_id",
+//	    "access_key_secret":"test_key_secret",
+//	    "sms": {
+//	        "phone_numbers":["13800138000"],
+//	        "sign_name":"测试签名",
+//	        "template_code":"SMS_0000",
+//	        "template_param":"{\"code\":\"888888\"}"
+//	    },
+//	    "single_call_by_tts": {
+//	        "called_show_number":"025000000",
+//	        "called_number":"13800138000",
+//	        "template_code":"TTS_0000",
+//	        "template_param":"{\"code\":\"888888\"}"
+//	    }
+//	}
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/northbright/aliyun/message"
+)
+
+// SMSConfig is the configuration for sending SMS.
+type SMSConfig struct {
+	PhoneNumbers  []string `json:"phone_numbers"`
+	SignName      string   `json:"sign_name"`
+	TemplateCode  string   `json:"template_code"`
+	TemplateParam string   `json:"template_param"`
+}
+
+// SingleCallByTTSConfig is the configuration for making single call by TTS.
+type SingleCallByTTSConfig struct {
+	CalledShowNumber string `json:"called_show_number"`
+	CalledNumber     string `json:"called_number"`
+	TemplateCode     string `json:"template_code"`
+	TemplateParam    string `json:"template_param"`
+}
+
+// Config is the configuration for aliyun message services.
+type Config struct {
+	AccessKeyID     string                `json:"access_key_id"`
+	AccessKeySecret string                `json:"access_key_secret"`
+	SMS             SMSConfig             `json:"sms"`
+	SingleCallByTTS SingleCallByTTSConfig `json:"single_call_by_tts"`
+}
+
+// Load loads the configuration from the JSON file.
+func Load(file string) (*Config, error) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("load config file error: %v", err)
+	}
+
+	config := &Config{}
+	if err = json.Unmarshal(buf, config); err != nil {
+		return nil, fmt.Errorf("parse config error: %v", err)
+	}
+
+	return config, nil
+}
+
+// Validate checks if the configuration is valid.
+// It checks credentials are present, phone numbers are well-formed and template params are valid JSON.
+// It returns an error which lists all problems or nil if no problem found.
+func (c *Config) Validate() error {
+	errs := []error{}
+
+	if strings.TrimSpace(c.AccessKeyID) == "" {
+		errs = append(errs, errors.New("empty access key ID"))
+	}
+	if strings.TrimSpace(c.AccessKeySecret) == "" {
+		errs = append(errs, errors.New("empty access key secret"))
+	}
+
+	for _, num := range c.SMS.PhoneNumbers {
+		if !isPhoneNumber(num) {
+			errs = append(errs, fmt.Errorf("invalid sms phone number: %q", num))
+		}
+	}
+	if c.SMS.TemplateParam != "" && !json.Valid([]byte(c.SMS.TemplateParam)) {
+		errs = append(errs, fmt.Errorf("invalid sms template param JSON: %q", c.SMS.TemplateParam))
+	}
+
+	if num := c.SingleCallByTTS.CalledShowNumber; num != "" && !isShowNumber(num) {
+		errs = append(errs, fmt.Errorf("invalid single call by TTS called show number: %q", num))
+	}
+	if num := c.SingleCallByTTS.CalledNumber; num != "" && !isPhoneNumber(num) {
+		errs = append(errs, fmt.Errorf("invalid single call by TTS called number: %q", num))
+	}
+	if c.SingleCallByTTS.TemplateParam != "" && !json.Valid([]byte(c.SingleCallByTTS.TemplateParam)) {
+		errs = append(errs, fmt.Errorf("invalid single call by TTS template param JSON: %q", c.SingleCallByTTS.TemplateParam))
+	}
+
+	return errors.Join(errs...)
+}
+
+// isPhoneNumber checks if the string is a phone number accepted by message.NormalizePhoneNumbers().
+func isPhoneNumber(s string) bool {
+	_, err := message.NormalizePhoneNumbers([]string{s})
+	return err == nil
+}
+
+// isShowNumber checks if the string is a called show number: digits only.
+// It's the landline or the 400 number assigned by aliyun. e.g. "025000000", which is not a mobile number.
+func isShowNumber(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
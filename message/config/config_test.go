@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/northbright/aliyun/message/config"
+)
+
+func TestLoad(t *testing.T) {
+	c, err := config.Load("../config.example.json")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	want := &config.Config{
+		AccessKeyID:     "test_key_id",
+		AccessKeySecret: "test_key_secret",
+		SMS: config.SMSConfig{
+			PhoneNumbers:  []string{"13800138000"},
+			SignName:      "测试签名",
+			TemplateCode:  "SMS_0000",
+			TemplateParam: `{"code":"888888"}`,
+		},
+		SingleCallByTTS: config.SingleCallByTTSConfig{
+			CalledShowNumber: "025000000",
+			CalledNumber:     "13800138000",
+			TemplateCode:     "TTS_0000",
+			TemplateParam:    `{"code":"888888"}`,
+		},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Load() = %+v, want %+v", c, want)
+	}
+
+	// Round trip.
+	buf, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+	got := &config.Config{}
+	if err = json.Unmarshal(buf, got); err != nil {
+		t.Fatalf("json.Unmarshal() error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+
+	if err = c.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []config.Config{
+		{AccessKeyID: "", AccessKeySecret: "test_key_secret"},
+		{AccessKeyID: "test_key_id", AccessKeySecret: " "},
+	}
+
+	for _, c := range tests {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate() for %+v returns no error", c)
+		}
+	}
+}
+
+func TestValidateMultipleIssues(t *testing.T) {
+	c := config.Config{
+		AccessKeyID: "test_key_id",
+		SMS: config.SMSConfig{
+			PhoneNumbers:  []string{"13800138000", "+852 0000 0000", "1380013800a", "", "12345"},
+			TemplateParam: `{"code":"888888"`,
+		},
+		SingleCallByTTS: config.SingleCallByTTSConfig{
+			CalledNumber:  "+8613800138000",
+			TemplateParam: `{"code":888888}`,
+		},
+	}
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatalf("Validate() returns no error")
+	}
+
+	issues := []string{
+		"empty access key secret",
+		`invalid sms phone number: "1380013800a"`,
+		`invalid sms phone number: ""`,
+		`invalid sms phone number: "12345"`,
+		"invalid sms template param JSON",
+	}
+	for _, issue := range issues {
+		if !strings.Contains(err.Error(), issue) {
+			t.Errorf("Validate() error: %v, want it reports %v", err, issue)
+		}
+	}
+	if n := len(strings.Split(err.Error(), "\n")); n != len(issues) {
+		t.Errorf("Validate() reports %v issues, want %v", n, len(issues))
+	}
+}
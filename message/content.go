@@ -0,0 +1,34 @@
+package message
+
+import (
+	"strings"
+)
+
+const (
+	// EncodingGSM7 is the GSM 7-bit default alphabet encoding of SMS content.
+	EncodingGSM7 = "GSM-7"
+	// EncodingUCS2 is the UCS-2 encoding of SMS content.
+	// It's used when the content contains any character which is not in GSM-7 alphabet(e.g. Chinese).
+	// One SMS segment holds fewer characters in UCS-2 than GSM-7.
+	EncodingUCS2 = "UCS-2"
+)
+
+const (
+	// gsm7Basic is the basic character set of GSM 03.38.
+	gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+		"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+	// gsm7Extension is the extension character set of GSM 03.38.
+	gsm7Extension = "\f^{}\\[~]|€"
+)
+
+// DetectEncoding detects the encoding of the rendered SMS content.
+// It returns EncodingGSM7 if all characters are in GSM-7 alphabet or EncodingUCS2 otherwise.
+// A single non GSM-7 character switches the content to the costlier UCS-2.
+func DetectEncoding(content string) string {
+	for _, r := range content {
+		if !strings.ContainsRune(gsm7Basic, r) && !strings.ContainsRune(gsm7Extension, r) {
+			return EncodingUCS2
+		}
+	}
+	return EncodingGSM7
+}
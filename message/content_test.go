@@ -0,0 +1,27 @@
+package message_test
+
+import (
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"", message.EncodingGSM7},
+		{"Your code is 1234.", message.EncodingGSM7},
+		{"Price: €10 {promo} [50%] ~ä", message.EncodingGSM7},
+		{"Your code is 1234 ✓", message.EncodingUCS2},
+		{"Code: 1234, 验证码", message.EncodingUCS2},
+		{"您的验证码是1234", message.EncodingUCS2},
+	}
+
+	for _, tt := range tests {
+		if got := message.DetectEncoding(tt.content); got != tt.want {
+			t.Errorf("DetectEncoding(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
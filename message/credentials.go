@@ -0,0 +1,79 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// EnvAccessKeyID is the environment variable of the access key ID by aliyun's convention.
+	EnvAccessKeyID = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	// EnvAccessKeySecret is the environment variable of the access key secret by aliyun's convention.
+	EnvAccessKeySecret = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	// EnvSecurityToken is the optional environment variable of the STS security token by aliyun's convention.
+	EnvSecurityToken = "ALIBABA_CLOUD_SECURITY_TOKEN"
+)
+
+var (
+	// ErrNoCredentialProvider is returned by requests of a client created without a credential provider.
+	ErrNoCredentialProvider = errors.New("no credential provider")
+	// ErrEmptyAccessKeyID is returned by NewClientChecked() if the access key ID is empty.
+	ErrEmptyAccessKeyID = errors.New("empty access key ID")
+	// ErrEmptyAccessKeySecret is returned by NewClientChecked() if the access key secret is empty.
+	ErrEmptyAccessKeySecret = errors.New("empty access key secret")
+)
+
+// CredentialProvider provides the credentials to sign requests.
+// e.g. rotate access keys via RAM roles.
+//
+// Credentials are fetched for each request(including retries), so implementations should cache them if it's costly.
+// securityToken is the STS security token of temporary credentials. It's empty for access keys.
+// It's included as "SecurityToken" parameter in the signed query if it's not empty.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (id, secret, securityToken string, err error)
+}
+
+// staticCredentials is the CredentialProvider of static access key ID, secret and optional security token.
+type staticCredentials struct {
+	id     string
+	secret string
+	token  string
+}
+
+// Credentials implements CredentialProvider.
+func (s staticCredentials) Credentials(ctx context.Context) (string, string, string, error) {
+	return s.id, s.secret, s.token, nil
+}
+
+// StaticCredentials returns the CredentialProvider of static access key ID and secret.
+// Both of them are generated by user in aliyun control panel.
+func StaticCredentials(id, secret string) CredentialProvider {
+	return staticCredentials{id: id, secret: secret}
+}
+
+// NewClientFromEnv creates a new client with the credentials read from the environment variables:
+// ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET and optional ALIBABA_CLOUD_SECURITY_TOKEN.
+// It returns an error if the required ones are missing.
+//
+// options: optional options for the client. e.g. WithHTTP2().
+func NewClientFromEnv(options ...Option) (*Client, error) {
+	id := os.Getenv(EnvAccessKeyID)
+	secret := os.Getenv(EnvAccessKeySecret)
+
+	missing := []string{}
+	if id == "" {
+		missing = append(missing, EnvAccessKeyID)
+	}
+	if secret == "" {
+		missing = append(missing, EnvAccessKeySecret)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	credentials := staticCredentials{id: id, secret: secret, token: os.Getenv(EnvSecurityToken)}
+	return NewClientWithProvider(credentials, options...), nil
+}
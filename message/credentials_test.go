@@ -0,0 +1,132 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+// rotatingCredentials returns a new access key for each request with the STS security token.
+type rotatingCredentials struct {
+	n int32
+}
+
+func (r *rotatingCredentials) Credentials(ctx context.Context) (string, string, string, error) {
+	n := atomic.AddInt32(&r.n, 1)
+	suffix := strings.Repeat("x", int(n))
+	return "STS.id" + suffix, "secret" + suffix, "token" + suffix, nil
+}
+
+func TestNewClientWithProvider(t *testing.T) {
+	requests := []*http.Request{}
+	client := message.NewClientWithProvider(&rotatingCredentials{})
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests = append(requests, req)
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+	}
+
+	// Credentials are fetched for each request.
+	for i, req := range requests {
+		suffix := strings.Repeat("x", i+1)
+		params, _ := sentParams(req)
+		if params["AccessKeyId"] != "STS.id"+suffix || params["SecurityToken"] != "token"+suffix {
+			t.Errorf("AccessKeyId = %v, SecurityToken = %v, want STS.id%v, token%v", params["AccessKeyId"], params["SecurityToken"], suffix, suffix)
+		}
+
+		assertSigned(t, req, "secret"+suffix)
+	}
+}
+
+// failedCredentials fails to provide the credentials.
+type failedCredentials struct{}
+
+var errCredentials = errors.New("failed to assume role")
+
+func (failedCredentials) Credentials(ctx context.Context) (string, string, string, error) {
+	return "", "", "", errCredentials
+}
+
+func TestNewClientWithProviderError(t *testing.T) {
+	tests := []struct {
+		provider message.CredentialProvider
+		err      error
+	}{
+		{failedCredentials{}, errCredentials},
+		{nil, message.ErrNoCredentialProvider},
+	}
+
+	for _, tt := range tests {
+		sent := false
+		client := message.NewClientWithProvider(tt.provider)
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = true
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		_, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("SendSMS() error = %v, want %v", err, tt.err)
+		}
+		if sent {
+			t.Errorf("SendSMS() sends the request without credentials")
+		}
+	}
+}
+
+func TestStaticCredentials(t *testing.T) {
+	id, secret, token, err := message.StaticCredentials("my_key_id", "my_key_secret").Credentials(context.Background())
+	if id != "my_key_id" || secret != "my_key_secret" || token != "" || err != nil {
+		t.Errorf("Credentials() = %v, %v, %v, %v, want my_key_id, my_key_secret, \"\", nil", id, secret, token, err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(message.EnvAccessKeyID, "env_key_id")
+	t.Setenv(message.EnvAccessKeySecret, "env_key_secret")
+	t.Setenv(message.EnvSecurityToken, "env_token")
+
+	client, err := message.NewClientFromEnv()
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() error: %v", err)
+	}
+
+	var req *http.Request
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+
+	params, _ := sentParams(req)
+	if params["AccessKeyId"] != "env_key_id" || params["SecurityToken"] != "env_token" {
+		t.Errorf("AccessKeyId = %v, SecurityToken = %v, want env_key_id, env_token", params["AccessKeyId"], params["SecurityToken"])
+	}
+
+	assertSigned(t, req, "env_key_secret")
+}
+
+func TestNewClientFromEnvMissing(t *testing.T) {
+	t.Setenv(message.EnvAccessKeyID, "env_key_id")
+	t.Setenv(message.EnvAccessKeySecret, "")
+
+	client, err := message.NewClientFromEnv()
+	if err == nil || !strings.Contains(err.Error(), message.EnvAccessKeySecret) {
+		t.Errorf("NewClientFromEnv() error = %v, want error of missing %v", err, message.EnvAccessKeySecret)
+	}
+	if client != nil {
+		t.Errorf("NewClientFromEnv() client = %v, want nil", client)
+	}
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrNoDefaultClient is returned by package-level functions if no default client is set.
+	ErrNoDefaultClient = errors.New("no default client set, call SetDefaultClient() first")
+
+	defaultClientMu sync.RWMutex
+	defaultClient   *Client
+)
+
+// SetDefaultClient sets the default client used by package-level functions. e.g. SendSMS().
+// It's useful for apps which use a single configured client.
+// Pass nil to unset the default client.
+func SetDefaultClient(c *Client) {
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+
+	defaultClient = c
+}
+
+// DefaultClient returns the default client. It returns nil if no one set.
+func DefaultClient() *Client {
+	defaultClientMu.RLock()
+	defer defaultClientMu.RUnlock()
+
+	return defaultClient
+}
+
+// SendSMS sends the SMS to phone numbers by the default client.
+// See Client.SendSMS() for parameters.
+// It returns ErrNoDefaultClient if no default client is set.
+func SendSMS(phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, error) {
+	c := DefaultClient()
+	if c == nil {
+		return false, nil, ErrNoDefaultClient
+	}
+	return c.SendSMS(phoneNumbers, signName, templateCode, templateParam, params...)
+}
+
+// SendSMSContext sends the SMS to phone numbers with the context by the default client.
+// See Client.SendSMSContext() for parameters.
+// It returns ErrNoDefaultClient if no default client is set.
+func SendSMSContext(ctx context.Context, phoneNumbers []string, signName, templateCode, templateParam string, params ...Param) (bool, *SMSResponse, error) {
+	c := DefaultClient()
+	if c == nil {
+		return false, nil, ErrNoDefaultClient
+	}
+	return c.SendSMSContext(ctx, phoneNumbers, signName, templateCode, templateParam, params...)
+}
+
+// MakeSingleCallByTTS makes the single call by TTS by the default client.
+// See Client.MakeSingleCallByTTS() for parameters.
+// It returns ErrNoDefaultClient if no default client is set.
+func MakeSingleCallByTTS(calledShowNumber, calledNumber, ttsCode, ttsParam string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
+	c := DefaultClient()
+	if c == nil {
+		return false, nil, ErrNoDefaultClient
+	}
+	return c.MakeSingleCallByTTS(calledShowNumber, calledNumber, ttsCode, ttsParam, params...)
+}
+
+// MakeSingleCallByTTSContext makes the single call by TTS with the context by the default client.
+// See Client.MakeSingleCallByTTSContext() for parameters.
+// It returns ErrNoDefaultClient if no default client is set.
+func MakeSingleCallByTTSContext(ctx context.Context, calledShowNumber, calledNumber, ttsCode, ttsParam string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
+	c := DefaultClient()
+	if c == nil {
+		return false, nil, ErrNoDefaultClient
+	}
+	return c.MakeSingleCallByTTSContext(ctx, calledShowNumber, calledNumber, ttsCode, ttsParam, params...)
+}
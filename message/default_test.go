@@ -0,0 +1,66 @@
+package message_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestDefaultClientUnset(t *testing.T) {
+	message.SetDefaultClient(nil)
+
+	if _, _, err := message.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != message.ErrNoDefaultClient {
+		t.Errorf("SendSMS() error = %v, want %v", err, message.ErrNoDefaultClient)
+	}
+	if _, _, err := message.SendSMSContext(context.Background(), []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != message.ErrNoDefaultClient {
+		t.Errorf("SendSMSContext() error = %v, want %v", err, message.ErrNoDefaultClient)
+	}
+	if _, _, err := message.MakeSingleCallByTTS("02560000000", "13800138000", "TTS_0000", `{"code":"1234"}`); err != message.ErrNoDefaultClient {
+		t.Errorf("MakeSingleCallByTTS() error = %v, want %v", err, message.ErrNoDefaultClient)
+	}
+	if _, _, err := message.MakeSingleCallByTTSContext(context.Background(), "02560000000", "13800138000", "TTS_0000", `{"code":"1234"}`); err != message.ErrNoDefaultClient {
+		t.Errorf("MakeSingleCallByTTSContext() error = %v, want %v", err, message.ErrNoDefaultClient)
+	}
+}
+
+func TestDefaultClientSet(t *testing.T) {
+	requests := 0
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return newStubResponse(http.StatusOK, `{"Code":"OK","BizId":"134523^4351232","CallId":"116012354148^10281378"}`), nil
+	})
+
+	message.SetDefaultClient(client)
+	defer message.SetDefaultClient(nil)
+
+	if message.DefaultClient() != client {
+		t.Errorf("DefaultClient() = %v, want %v", message.DefaultClient(), client)
+	}
+
+	ok, smsResp, err := message.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if err != nil || !ok || smsResp.BizID != "134523^4351232" {
+		t.Errorf("SendSMS() = %v, %v, %v, want OK response", ok, smsResp, err)
+	}
+
+	ok, smsResp, err = message.SendSMSContext(context.Background(), []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if err != nil || !ok || smsResp.BizID != "134523^4351232" {
+		t.Errorf("SendSMSContext() = %v, %v, %v, want OK response", ok, smsResp, err)
+	}
+
+	ok, vmsResp, err := message.MakeSingleCallByTTS("02560000000", "13800138000", "TTS_0000", `{"code":"1234"}`)
+	if err != nil || !ok || vmsResp.CallID != "116012354148^10281378" {
+		t.Errorf("MakeSingleCallByTTS() = %v, %v, %v, want OK response", ok, vmsResp, err)
+	}
+
+	ok, vmsResp, err = message.MakeSingleCallByTTSContext(context.Background(), "02560000000", "13800138000", "TTS_0000", `{"code":"1234"}`)
+	if err != nil || !ok || vmsResp.CallID != "116012354148^10281378" {
+		t.Errorf("MakeSingleCallByTTSContext() = %v, %v, %v, want OK response", ok, vmsResp, err)
+	}
+
+	if requests != 4 {
+		t.Errorf("requests = %v, want 4", requests)
+	}
+}
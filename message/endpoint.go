@@ -0,0 +1,91 @@
+package message
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+// Services of aliyun for EndpointForService().
+const (
+	// ServiceSMS is the SMS service.
+	ServiceSMS = "sms"
+	// ServiceVoice is the voice messaging service.
+	ServiceVoice = "voice"
+)
+
+// Endpoints maps region IDs to hosts of the SMS and voice messaging services.
+// The host is empty if the service is not available in the region.
+// Add or override the entries before sending requests to use other regions or private endpoints.
+var Endpoints = map[string]struct{ SMS, Voice string }{
+	"cn-hangzhou":    {SMS: smsHost, Voice: voiceHost},
+	"cn-beijing":     {SMS: smsHost, Voice: voiceHost},
+	"cn-shanghai":    {SMS: smsHost, Voice: voiceHost},
+	"cn-shenzhen":    {SMS: smsHost, Voice: voiceHost},
+	"ap-southeast-1": {SMS: "dysmsapi.ap-southeast-1.aliyuncs.com"},
+	"ap-southeast-5": {SMS: "dysmsapi.ap-southeast-5.aliyuncs.com"},
+}
+
+// regionIDPattern matches well-formed region IDs. e.g. "ap-northeast-1".
+var regionIDPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// EndpointForService returns the host of the service for the region by Endpoints.
+//
+// regionID: region ID. It's "cn-hangzhou" if it's empty.
+// service: ServiceSMS or ServiceVoice.
+//
+// It returns an error if the region is unknown or the service is not available in the region.
+// Add the region to Endpoints or specify the host by Endpoint() to use other regions. See EndpointForRegion().
+func EndpointForService(regionID, service string) (string, error) {
+	if regionID == "" {
+		regionID = DefaultRegionID
+	}
+
+	endpoints, ok := Endpoints[regionID]
+	if !ok {
+		return "", fmt.Errorf("unknown region: %q", regionID)
+	}
+
+	host := ""
+	switch service {
+	case ServiceSMS:
+		host = endpoints.SMS
+	case ServiceVoice:
+		host = endpoints.Voice
+	default:
+		return "", fmt.Errorf("unknown service: %q", service)
+	}
+
+	if host == "" {
+		return "", fmt.Errorf("service %s is not available in region %q", service, regionID)
+	}
+	return host, nil
+}
+
+// EndpointForRegion returns the host of SMS service API for the region.
+// It's the host used by the client for regions in Endpoints. See EndpointForService().
+// e.g. "dysmsapi.aliyuncs.com" for "cn-hangzhou", "dysmsapi.ap-southeast-1.aliyuncs.com" for "ap-southeast-1".
+//
+// The host of the well-formed region ID which is not in Endpoints is derived from it.
+// e.g. "dysmsapi.ap-northeast-1.aliyuncs.com" for "ap-northeast-1".
+// The client never sends requests to it unless it's specified by Endpoint().
+// The default host "dysmsapi.aliyuncs.com" is returned for malformed region IDs.
+func EndpointForRegion(regionID string) string {
+	host, err := EndpointForService(regionID, ServiceSMS)
+	if err == nil {
+		return host
+	}
+	if !regionIDPattern.MatchString(regionID) {
+		return smsHost
+	}
+	return "dysmsapi." + regionID + ".aliyuncs.com"
+}
+
+// serviceHost returns the host of the service for the "RegionId" parameter.
+// The explicit endpoint specified by Endpoint() is used without checking the region.
+func serviceHost(v url.Values, o *requestOptions, service string) (string, error) {
+	if o.endpoint != "" {
+		return o.endpoint, nil
+	}
+	return EndpointForService(v.Get("RegionId"), service)
+}
@@ -0,0 +1,84 @@
+package message_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestEndpointForService(t *testing.T) {
+	tests := []struct {
+		regionID string
+		service  string
+		want     string
+		err      bool
+	}{
+		{"", message.ServiceSMS, "dysmsapi.aliyuncs.com", false},
+		{"", message.ServiceVoice, "dyvmsapi.aliyuncs.com", false},
+		{"cn-hangzhou", message.ServiceVoice, "dyvmsapi.aliyuncs.com", false},
+		{"ap-southeast-1", message.ServiceSMS, "dysmsapi.ap-southeast-1.aliyuncs.com", false},
+		// Voice messaging service is not available in the region.
+		{"ap-southeast-1", message.ServiceVoice, "", true},
+		// Unknown region. The SMS host is never derived.
+		{"ap-northeast-1", message.ServiceSMS, "", true},
+		{"ap-northeast-1", message.ServiceVoice, "", true},
+		// Malformed region ID.
+		{"mars-1.example.com/", message.ServiceSMS, "", true},
+		// Unknown service.
+		{"cn-hangzhou", "email", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := message.EndpointForService(tt.regionID, tt.service)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("EndpointForService(%q, %q) = %v, %v, want %v, error: %v", tt.regionID, tt.service, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestUnknownRegion(t *testing.T) {
+	hosts := []string{}
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		hosts = append(hosts, req.URL.Host)
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	// The request is not sent to the default host for the unknown region.
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.RegionID("mars 1")); err == nil {
+		t.Errorf("SendSMS() with malformed region returns no error")
+	}
+	if _, _, err := client.MakeSingleCallByTTS("057100000000", "13800138000", "TTS_0000", `{"code":"1234"}`, message.RegionID("mars-1")); err == nil {
+		t.Errorf("MakeSingleCallByTTS() with unknown region returns no error")
+	}
+	if len(hosts) != 0 {
+		t.Fatalf("hosts = %v, want no request", hosts)
+	}
+
+	// The explicit endpoint is used without checking the region.
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.RegionID("mars-1"), message.Endpoint("sms.example.com")); err != nil {
+		t.Errorf("SendSMS() with explicit endpoint error: %v", err)
+	}
+
+	// The region which is not in the endpoints is rejected, but its derived host can be specified.
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.RegionID("ap-northeast-1")); err == nil {
+		t.Errorf("SendSMS() with unmapped region returns no error")
+	}
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.RegionID("ap-northeast-1"), message.Endpoint(message.EndpointForRegion("ap-northeast-1"))); err != nil {
+		t.Errorf("SendSMS() with the endpoint of unmapped region error: %v", err)
+	}
+
+	// Add the region to the endpoints.
+	message.Endpoints["mars-1"] = struct{ SMS, Voice string }{SMS: "sms.mars-1.example.com"}
+	defer delete(message.Endpoints, "mars-1")
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.RegionID("mars-1")); err != nil {
+		t.Errorf("SendSMS() with added region error: %v", err)
+	}
+
+	want := []string{"sms.example.com", "dysmsapi.ap-northeast-1.aliyuncs.com", "sms.mars-1.example.com"}
+	if strings.Join(hosts, ",") != strings.Join(want, ",") {
+		t.Errorf("hosts = %v, want %v", hosts, want)
+	}
+}
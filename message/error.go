@@ -0,0 +1,65 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSignIllegal is matched by errors.Is for an *APIError of an unregistered or unapproved sign name.
+	// e.g. "isv.SMS_SIGN_ILLEGAL", "isv.SMS_SIGNATURE_ILLEGAL".
+	ErrSignIllegal = errors.New("sign name is not registered or approved: add it by AddSmsSign() and check it by QuerySmsSignStatus()")
+	// ErrTemplateIllegal is matched by errors.Is for an *APIError of an unregistered or unapproved template.
+	// e.g. "isv.SMS_TEMPLATE_ILLEGAL".
+	ErrTemplateIllegal = errors.New("template is not registered or approved: add it by AddSmsTemplate() and check it by QuerySmsTemplate()")
+)
+
+// APIError is the error of a response whose status code is not "OK".
+type APIError struct {
+	// Code is the status code. e.g. "isv.BUSINESS_LIMIT_CONTROL".
+	Code string
+	// Message is the detail message for the status code.
+	Message string
+	// RequestID is the request ID.
+	// It's the "X-Acs-Request-Id" header of the HTTP response if the request ID of the body is empty.
+	RequestID string
+	// HTTPStatusCode is the status code of the HTTP response.
+	HTTPStatusCode int
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("aliyun API error: code: %s, message: %s, request ID: %s", e.Code, e.Message, e.RequestID)
+}
+
+// Is reports whether the status code of the error maps to target.
+// It makes errors.Is(err, ErrSignIllegal) and errors.Is(err, ErrTemplateIllegal) work.
+func (e *APIError) Is(target error) bool {
+	err, ok := remediationErrors[e.Code]
+	return ok && err == target
+}
+
+// newAPIError returns the APIError of the response.
+func newAPIError(r *Response) *APIError {
+	requestID := r.RequestID
+	if requestID == "" {
+		requestID = r.AcsRequestID
+	}
+
+	return &APIError{
+		Code:           r.Code,
+		Message:        r.Message,
+		RequestID:      requestID,
+		HTTPStatusCode: r.HTTPStatusCode,
+	}
+}
+
+// IsThrottled reports whether the error is an *APIError of a request denied by aliyun's flow control.
+// e.g. "isv.BUSINESS_LIMIT_CONTROL", "Throttling.User".
+func IsThrottled(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return throttlingCodes[apiErr.Code]
+}
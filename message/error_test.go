@@ -0,0 +1,115 @@
+package message_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestAPIError(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"isv.MOBILE_NUMBER_ILLEGAL","Message":"invalid mobile number"}`), nil
+	})
+
+	ok, resp, err := client.SendSMS([]string{"1380013800a"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if ok {
+		t.Errorf("SendSMS() ok = true, want false")
+	}
+	if resp == nil || resp.Code != "isv.MOBILE_NUMBER_ILLEGAL" {
+		t.Errorf("SendSMS() response = %v, want the parsed response", resp)
+	}
+
+	var apiErr *message.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("SendSMS() error = %v, want *message.APIError", err)
+	}
+	want := message.APIError{Code: "isv.MOBILE_NUMBER_ILLEGAL", Message: "invalid mobile number", RequestID: "8906582E-6722", HTTPStatusCode: http.StatusOK}
+	if *apiErr != want {
+		t.Errorf("APIError = %+v, want %+v", *apiErr, want)
+	}
+	if message.IsThrottled(err) {
+		t.Errorf("IsThrottled(%v) = true, want false", err)
+	}
+}
+
+func TestAPIErrorAcsRequestID(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := newStubResponse(http.StatusBadRequest, `{"Code":"SignatureDoesNotMatch","Message":"Specified signature is not matched with our calculation."}`)
+		resp.Header.Set("X-Acs-Request-Id", "ACS-6722")
+		return resp, nil
+	})
+
+	_, resp, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if resp == nil || resp.HTTPStatusCode != http.StatusBadRequest || resp.AcsRequestID != "ACS-6722" {
+		t.Fatalf("SendSMS() response = %+v, want HTTP status code 400 and X-Acs-Request-Id ACS-6722", resp)
+	}
+
+	// The header is used if the request ID of the body is empty.
+	var apiErr *message.APIError
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "ACS-6722" || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Errorf("SendSMS() error = %+v, want *message.APIError with request ID ACS-6722 and HTTP status code 400", err)
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("isv.BUSINESS_LIMIT_CONTROL"), false},
+		{&message.APIError{Code: "isv.BUSINESS_LIMIT_CONTROL"}, true},
+		{&message.APIError{Code: "Throttling.User"}, true},
+		{fmt.Errorf("send error: %w", &message.APIError{Code: "Throttling.User"}), true},
+		{&message.APIError{Code: "isv.MOBILE_NUMBER_ILLEGAL"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := message.IsThrottled(tt.err); got != tt.want {
+			t.Errorf("IsThrottled(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	tests := []struct {
+		code   string
+		target error
+		want   bool
+	}{
+		{"isv.SMS_SIGN_ILLEGAL", message.ErrSignIllegal, true},
+		{"isv.SMS_SIGNATURE_ILLEGAL", message.ErrSignIllegal, true},
+		{"isv.SMS_TEMPLATE_ILLEGAL", message.ErrTemplateIllegal, true},
+		{"isv.SMS_SIGN_ILLEGAL", message.ErrTemplateIllegal, false},
+		{"isv.SMS_TEMPLATE_ILLEGAL", message.ErrSignIllegal, false},
+		{"isv.MOBILE_NUMBER_ILLEGAL", message.ErrSignIllegal, false},
+		{"isv.MOBILE_NUMBER_ILLEGAL", message.ErrTemplateIllegal, false},
+	}
+
+	for _, tt := range tests {
+		err := fmt.Errorf("send error: %w", &message.APIError{Code: tt.code})
+		if got := errors.Is(err, tt.target); got != tt.want {
+			t.Errorf("errors.Is(%v, %v) = %v, want %v", err, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestAPIErrorSignIllegal(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"isv.SMS_SIGN_ILLEGAL","Message":"invalid sign name"}`), nil
+	})
+
+	_, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if !errors.Is(err, message.ErrSignIllegal) {
+		t.Errorf("SendSMS() error = %v, want errors.Is(err, ErrSignIllegal)", err)
+	}
+	if errors.Is(err, message.ErrTemplateIllegal) {
+		t.Errorf("SendSMS() error = %v, want !errors.Is(err, ErrTemplateIllegal)", err)
+	}
+}
@@ -0,0 +1,140 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// globeHost is the host of aliyun SMS service API for international messages.
+	globeHost = "dysmsapi.ap-southeast-1.aliyuncs.com"
+	// globeVersion is the API version of international messages.
+	globeVersion = "2018-05-01"
+	// globeRegionID is the region ID of international messages.
+	globeRegionID = "ap-southeast-1"
+)
+
+// NumberDetail is the detail of the phone number of the international message.
+type NumberDetail struct {
+	// Country is the country of the phone number. e.g. "Hongkong, China".
+	Country string `json:"Country" xml:"Country"`
+	// Region is the region of the phone number. e.g. "HongKong".
+	Region string `json:"Region" xml:"Region"`
+	// Carrier is the carrier of the phone number. e.g. "CMI".
+	Carrier string `json:"Carrier" xml:"Carrier"`
+}
+
+// SendMessageToGlobeResponse is the response of HTTP request of sending the international message.
+// Code and Message of the embedded Response are set by ResponseCode and ResponseDescription.
+type SendMessageToGlobeResponse struct {
+	Response
+	// ResponseCode is the status code. e.g. "OK".
+	ResponseCode string `json:"ResponseCode" xml:"ResponseCode"`
+	// ResponseDescription is the detail message for the status code.
+	ResponseDescription string `json:"ResponseDescription" xml:"ResponseDescription"`
+	// MessageID is the ID of the message. e.g. "1008030300****".
+	MessageID string `json:"MessageId" xml:"MessageId"`
+	// To is the phone number which the message sent to. e.g. "85200000000".
+	To string `json:"To" xml:"To"`
+	// From is the sender ID.
+	From string `json:"From" xml:"From"`
+	// Segments is the number of segments of the message. e.g. "1".
+	Segments string `json:"Segments" xml:"Segments"`
+	// NumberDetail is the detail of the phone number.
+	NumberDetail NumberDetail `json:"NumberDetail" xml:"NumberDetail"`
+}
+
+// globeResponse has the fields of SendMessageToGlobeResponse without the methods.
+type globeResponse SendMessageToGlobeResponse
+
+// UnmarshalJSON parses the JSON response.
+func (r *SendMessageToGlobeResponse) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*globeResponse)(r)); err != nil {
+		return err
+	}
+	r.setCommon()
+	return nil
+}
+
+// UnmarshalXML parses the XML response.
+func (r *SendMessageToGlobeResponse) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if err := d.DecodeElement((*globeResponse)(r), &start); err != nil {
+		return err
+	}
+	r.setCommon()
+	return nil
+}
+
+// setCommon sets the status code and the message of the common response.
+// International messages use "ResponseCode" and "ResponseDescription" instead of "Code" and "Message".
+func (r *SendMessageToGlobeResponse) setCommon() {
+	if r.Code == "" {
+		r.Code = r.ResponseCode
+	}
+	if r.Message == "" {
+		r.Message = r.ResponseDescription
+	}
+}
+
+// SendMessageToGlobe sends the international message to the phone number without the template.
+// It's sent by POST to the international endpoint "dysmsapi.ap-southeast-1.aliyuncs.com".
+// Use Endpoint() and Method() to override them.
+//
+// to: phone number with the country code. e.g. "85200000000".
+// from: optional sender ID. Pass "" to use the default one.
+// message: content of the message.
+// params: optional parameters for sending the international message.
+//
+// The price of the message depends on the country and the number of segments.
+// It's not checked or limited here.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) SendMessageToGlobe(to, from, message string, params ...Param) (bool, *SendMessageToGlobeResponse, error) {
+	return c.SendMessageToGlobeContext(context.Background(), to, from, message, params...)
+}
+
+// SendMessageToGlobeContext sends the international message with the context.
+// See SendMessageToGlobe() for other parameters.
+func (c *Client) SendMessageToGlobeContext(ctx context.Context, to, from, message string, params ...Param) (bool, *SendMessageToGlobeResponse, error) {
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for sending international messages.
+	v.Set("Action", "SendMessageToGlobe")
+	v.Set("Version", globeVersion)
+	v.Set("RegionId", globeRegionID)
+
+	// Set required business parameters
+	v.Set("To", to)
+	if from != "" {
+		v.Set("From", from)
+	}
+	v.Set("Message", message)
+
+	// Override parameters if need.
+	o, err := applyParams(v, params)
+	if err != nil {
+		return false, nil, err
+	}
+	ignoreVoiceParams(v)
+
+	if o.method == "" {
+		o.method = http.MethodPost
+	}
+	host := globeHost
+	if o.endpoint != "" {
+		host = o.endpoint
+	}
+
+	response := &SendMessageToGlobeResponse{}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
@@ -0,0 +1,78 @@
+package message_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestSendMessageToGlobe(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"ResponseCode":"OK","NumberDetail":{"Carrier":"CMI","Region":"HongKong","Country":"Hongkong, China"},"RequestId":"F655A8D5-B967-440B-8683-DAD6FF8DE990","Segments":"1","ResponseDescription":"OK","To":"85200000000","MessageId":"1008030300****"}`},
+		{"XML", `<SendMessageToGlobeResponse><ResponseCode>OK</ResponseCode><NumberDetail><Carrier>CMI</Carrier><Region>HongKong</Region><Country>Hongkong, China</Country></NumberDetail><RequestId>F655A8D5-B967-440B-8683-DAD6FF8DE990</RequestId><Segments>1</Segments><ResponseDescription>OK</ResponseDescription><To>85200000000</To><MessageId>1008030300****</MessageId></SendMessageToGlobeResponse>`},
+	}
+
+	for _, tt := range tests {
+		var req *http.Request
+		var form url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			req = r
+			body, _ := io.ReadAll(r.Body)
+			form, _ = url.ParseQuery(string(body))
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.SendMessageToGlobe("85200000000", "", "Your code is 1234", message.Format(tt.format))
+		if !ok || err != nil {
+			t.Fatalf("SendMessageToGlobe() with format %v = %v, %v, %v, want OK response", tt.format, ok, resp, err)
+		}
+
+		// It's sent by POST to the international endpoint.
+		if req.Method != http.MethodPost || req.URL.Host != "dysmsapi.ap-southeast-1.aliyuncs.com" {
+			t.Errorf("request = %v %v, want POST to dysmsapi.ap-southeast-1.aliyuncs.com", req.Method, req.URL)
+		}
+		for key, want := range map[string]string{
+			"Action":   "SendMessageToGlobe",
+			"Version":  "2018-05-01",
+			"RegionId": "ap-southeast-1",
+			"To":       "85200000000",
+			"Message":  "Your code is 1234",
+		} {
+			if got := form.Get(key); got != want {
+				t.Errorf("%v = %v, want %v", key, got, want)
+			}
+		}
+		if _, ok := form["From"]; ok {
+			t.Errorf("From = %v, want no sender ID", form.Get("From"))
+		}
+
+		want := message.NumberDetail{Country: "Hongkong, China", Region: "HongKong", Carrier: "CMI"}
+		if resp.Code != "OK" || resp.MessageID != "1008030300****" || resp.To != "85200000000" || resp.Segments != "1" || resp.NumberDetail != want {
+			t.Errorf("SendMessageToGlobe() with format %v = %+v, want message 1008030300**** to 85200000000", tt.format, resp)
+		}
+	}
+}
+
+func TestSendMessageToGlobeError(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"ResponseCode":"InvalidPhoneNumber","ResponseDescription":"invalid phone number","RequestId":"F655A8D5-B967-440B-8683-DAD6FF8DE990"}`), nil
+	})
+
+	ok, resp, err := client.SendMessageToGlobe("852", "Alicloud", "Your code is 1234")
+	var apiErr *message.APIError
+	if ok || !errors.As(err, &apiErr) || apiErr.Code != "InvalidPhoneNumber" || apiErr.Message != "invalid phone number" {
+		t.Errorf("SendMessageToGlobe() = %v, %v, want *message.APIError of InvalidPhoneNumber", ok, err)
+	}
+	if resp == nil || resp.ResponseCode != "InvalidPhoneNumber" {
+		t.Errorf("SendMessageToGlobe() response = %v, want the parsed response", resp)
+	}
+}
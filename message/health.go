@@ -0,0 +1,92 @@
+package message
+
+import (
+	"context"
+	"time"
+)
+
+// AuditHealth is the audit status of a signature name or a template in the HealthReport.
+type AuditHealth struct {
+	// Name is the signature name or the template code.
+	Name string
+	// Status is the audit status: AuditStatusAuditing, AuditStatusApproved or AuditStatusRejected.
+	Status int
+	// Reason is the reason of the rejection.
+	Reason string
+	// Err is the error of the query. Status and Reason are unknown if it's not nil.
+	// It's an *APIError if the status code of the response is not "OK".
+	Err error
+}
+
+// HealthReport is the consolidated audit status of signature names and templates returned by Client.HealthReport().
+type HealthReport struct {
+	// Signs are the audit statuses of signature names in the same order of signature names.
+	Signs []AuditHealth
+	// Templates are the audit statuses of templates in the same order of template codes.
+	Templates []AuditHealth
+}
+
+// healthReportInterval is the min interval between queries of HealthReport() to avoid throttling.
+const healthReportInterval = queryAllPageDelay
+
+// HealthReport queries the audit status of all signature names and templates concurrently by a pool of workers.
+// It's for onboarding dashboards which need the audit status of all signature names and templates used by the app.
+//
+// signNames: signature names to query by QuerySmsSignStatus().
+// templateCodes: template codes to query by QuerySmsTemplate().
+// concurrency: max number of concurrent requests. It's 1 if it's less than 1.
+// params: optional parameters for the queries.
+//
+// Queries are started at most once per 50ms to avoid throttling.
+// A failed query doesn't abort the report: its error is in Err of the entry and other entries are still queried.
+// Entries which are not queried when the context is canceled get the error of the context.
+func (c *Client) HealthReport(ctx context.Context, signNames, templateCodes []string, concurrency int, params ...Param) HealthReport {
+	report := HealthReport{
+		Signs:     make([]AuditHealth, len(signNames)),
+		Templates: make([]AuditHealth, len(templateCodes)),
+	}
+
+	ticker := time.NewTicker(healthReportInterval)
+	defer ticker.Stop()
+
+	runPool(len(signNames)+len(templateCodes), concurrency, func(i int) {
+		if i < len(signNames) {
+			h := &report.Signs[i]
+			h.Name = signNames[i]
+			if h.Err = waitTick(ctx, ticker); h.Err != nil {
+				return
+			}
+
+			var ok bool
+			var resp *QuerySmsSignResponse
+			if ok, resp, h.Err = c.QuerySmsSignStatusContext(ctx, h.Name, params...); ok {
+				h.Status, h.Reason = resp.SignStatus, resp.Reason
+			}
+			return
+		}
+
+		h := &report.Templates[i-len(signNames)]
+		h.Name = templateCodes[i-len(signNames)]
+		if h.Err = waitTick(ctx, ticker); h.Err != nil {
+			return
+		}
+
+		var ok bool
+		var resp *QuerySmsTemplateResponse
+		if ok, resp, h.Err = c.QuerySmsTemplateContext(ctx, h.Name, params...); ok {
+			h.Status, h.Reason = resp.TemplateStatus, resp.Reason
+		}
+	})
+
+	return report
+}
+
+// waitTick waits for the next tick of the ticker or until the context is done.
+func waitTick(ctx context.Context, ticker *time.Ticker) error {
+	select {
+	case <-ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
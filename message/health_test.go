@@ -0,0 +1,99 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestHealthReport(t *testing.T) {
+	signs := map[string]string{
+		"approved_sign": `"SignStatus":1,"Reason":""`,
+		"pending_sign":  `"SignStatus":0,"Reason":""`,
+		"rejected_sign": `"SignStatus":2,"Reason":"文件不能证明信息真实性，请重新上传"`,
+	}
+	templates := map[string]string{
+		"SMS_0001": `"TemplateStatus":1,"Reason":""`,
+		"SMS_0002": `"TemplateStatus":2,"Reason":"模板内容不符合规范"`,
+	}
+
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch query.Get("Action") {
+		case "QuerySmsSign":
+			return newStubResponse(http.StatusOK, fmt.Sprintf(`{"RequestId":"STUB","Code":"OK","Message":"OK","SignName":%q,%s}`, query.Get("SignName"), signs[query.Get("SignName")])), nil
+		case "QuerySmsTemplate":
+			return newStubResponse(http.StatusOK, fmt.Sprintf(`{"RequestId":"STUB","Code":"OK","Message":"OK","TemplateCode":%q,%s}`, query.Get("TemplateCode"), templates[query.Get("TemplateCode")])), nil
+		}
+		return nil, fmt.Errorf("unexpected action %v", query.Get("Action"))
+	})
+
+	report := client.HealthReport(context.Background(), []string{"approved_sign", "pending_sign", "rejected_sign"}, []string{"SMS_0001", "SMS_0002"}, 2)
+	want := message.HealthReport{
+		Signs: []message.AuditHealth{
+			{Name: "approved_sign", Status: message.AuditStatusApproved},
+			{Name: "pending_sign", Status: message.AuditStatusAuditing},
+			{Name: "rejected_sign", Status: message.AuditStatusRejected, Reason: "文件不能证明信息真实性，请重新上传"},
+		},
+		Templates: []message.AuditHealth{
+			{Name: "SMS_0001", Status: message.AuditStatusApproved},
+			{Name: "SMS_0002", Status: message.AuditStatusRejected, Reason: "模板内容不符合规范"},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("HealthReport() = %+v, want %+v", report, want)
+	}
+}
+
+func TestHealthReportPartialFailure(t *testing.T) {
+	errNetwork := errors.New("network is unreachable")
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		switch {
+		case query.Get("SignName") == "unreachable_sign":
+			return nil, errNetwork
+		case query.Get("Action") == "QuerySmsSign":
+			return newStubResponse(http.StatusOK, `{"RequestId":"STUB","Code":"OK","Message":"OK","SignStatus":1}`), nil
+		}
+		return newStubResponse(http.StatusOK, `{"RequestId":"STUB","Code":"OK","Message":"OK","TemplateStatus":1}`), nil
+	})
+
+	report := client.HealthReport(context.Background(), []string{"approved_sign", "unreachable_sign"}, []string{"SMS_0001"}, 2)
+	if len(report.Signs) != 2 || len(report.Templates) != 1 {
+		t.Fatalf("HealthReport() = %+v, want 2 signs and 1 template", report)
+	}
+
+	// The failed query doesn't abort the others.
+	if h := report.Signs[1]; h.Name != "unreachable_sign" || !errors.Is(h.Err, errNetwork) {
+		t.Errorf("Signs[1] = %+v, want the error of the unreachable sign", h)
+	}
+	for _, h := range []message.AuditHealth{report.Signs[0], report.Templates[0]} {
+		if h.Err != nil || h.Status != message.AuditStatusApproved {
+			t.Errorf("entry = %+v, want approved without error", h)
+		}
+	}
+}
+
+func TestHealthReportCanceled(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		t.Errorf("request %v is sent after the context is canceled", req.URL)
+		return nil, errors.New("unexpected request")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := client.HealthReport(ctx, []string{"approved_sign"}, []string{"SMS_0001"}, 2)
+	for _, h := range append(report.Signs, report.Templates...) {
+		if !errors.Is(h.Err, context.Canceled) {
+			t.Errorf("entry = %+v, want context.Canceled", h)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package message
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+// logRequest logs the signed request at debug level if the logger is set.
+// Credentials are masked and the signature is truncated.
+func (c *Client) logRequest(ctx context.Context, v url.Values, signature string) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	// Mask credentials in the canonicalized query string.
+	masked := url.Values{}
+	for key, values := range v {
+		masked[key] = values
+	}
+	for _, key := range []string{"AccessKeyId", "SecurityToken"} {
+		if _, ok := masked[key]; ok {
+			masked.Set(key, mask(masked.Get(key)))
+		}
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "aliyun request",
+		slog.String("action", v.Get("Action")),
+		slog.Int("phoneCount", phoneCount(v.Get("PhoneNumbers"))),
+		slog.String("signatureMethod", v.Get("SignatureMethod")),
+		slog.String("canonicalQuery", canonicalQuery(masked)),
+		slog.String("signature", truncate(signature)),
+	)
+}
+
+// logResponse logs the parsed response at debug level if the logger is set.
+func (c *Client) logResponse(ctx context.Context, v url.Values, r *Response) {
+	if c.logger == nil {
+		return
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "aliyun response",
+		slog.String("action", v.Get("Action")),
+		slog.Int("httpStatusCode", r.HTTPStatusCode),
+		slog.String("code", r.Code),
+		slog.String("requestId", r.RequestID),
+	)
+}
+
+// mask keeps the first 4 characters of the credential and masks the rest.
+// e.g. "LTAI4Fxxxxxxxxxx" -> "LTAI****".
+func mask(s string) string {
+	if len(s) <= 4 {
+		return "****"
+	}
+	return s[:4] + "****"
+}
+
+// maskSecret keeps the first 2 and the last 2 characters of the secret and masks the rest.
+// e.g. "my_key_secret" -> "my****et". Short secrets are masked entirely.
+func maskSecret(s string) string {
+	if len(s) < 8 {
+		return "****"
+	}
+	return s[:2] + "****" + s[len(s)-2:]
+}
+
+// truncate keeps the first 6 characters of the signature.
+// e.g. "zJDF%2BLrzhj%2FThnlvIToysFRq6t4%3D" -> "zJDF%2...".
+func truncate(signature string) string {
+	if len(signature) <= 6 {
+		return signature
+	}
+	return signature[:6] + "..."
+}
+
+// phoneCount returns the number of phone numbers in the "PhoneNumbers" parameter.
+func phoneCount(phoneNumbers string) int {
+	if phoneNumbers == "" {
+		return 0
+	}
+	return len(strings.Split(phoneNumbers, ","))
+}
@@ -0,0 +1,36 @@
+package message
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/northbright/uuid"
+)
+
+// NonceSource generates the nonce for "SignatureNonce" parameter.
+// Each call should return a unique nonce.
+type NonceSource func() string
+
+// UUIDNonceSource generates UUID as nonce. It's the default nonce source.
+func UUIDNonceSource() string {
+	UUID, _ := uuid.New()
+	return UUID
+}
+
+// InstanceNonceSource returns a nonce source which generates nonces
+// in the form of "<instance ID>-<start time>-<counter>".
+//
+// In multi-instance deployments, random nonces generated by two instances may collide in theory.
+// The nonces generated by this source never collide as long as each instance uses a unique instance ID.
+// The start time of the source avoids collisions after an instance restarts and its counter begins from zero again.
+// The tradeoff is that nonces are predictable and expose the instance ID to aliyun.
+func InstanceNonceSource(instanceID string) NonceSource {
+	start := time.Now().UnixNano()
+	var counter uint64
+
+	return func() string {
+		n := atomic.AddUint64(&counter, 1)
+		return fmt.Sprintf("%s-%d-%d", instanceID, start, n)
+	}
+}
@@ -0,0 +1,76 @@
+package message_test
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestInstanceNonceSource(t *testing.T) {
+	source := message.InstanceNonceSource("node-1")
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		nonces = map[string]bool{}
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				nonce := source()
+				mu.Lock()
+				nonces[nonce] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(nonces) != 1000 {
+		t.Errorf("unique nonces = %v, want 1000", len(nonces))
+	}
+	for nonce := range nonces {
+		if !strings.HasPrefix(nonce, "node-1-") {
+			t.Errorf("nonce %v has no instance prefix", nonce)
+			break
+		}
+	}
+}
+
+func TestWithNonceSource(t *testing.T) {
+	nonce := ""
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithNonceSource(message.InstanceNonceSource("node-1")))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		nonce = req.URL.Query().Get("SignatureNonce")
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+	if !strings.HasPrefix(nonce, "node-1-") {
+		t.Errorf("SignatureNonce = %v, want prefix node-1-", nonce)
+	}
+}
+
+func TestWithNonceSourceNil(t *testing.T) {
+	nonce := ""
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithNonceSource(nil))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		nonce = req.URL.Query().Get("SignatureNonce")
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	// The default nonce source is kept.
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+	if nonce == "" {
+		t.Errorf("SignatureNonce is empty, want the one of UUIDNonceSource")
+	}
+}
@@ -0,0 +1,238 @@
+package message
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/northbright/aliyun/internal/pop"
+)
+
+// Option is the option for creating a new client.
+// Use option helper functions to get specified Option. e.g. WithHTTP2().
+type Option func(c *Client)
+
+// WithHTTP2 enables or disables HTTP/2 for HTTPS requests.
+// HTTP/2 is negotiated automatically by the default transport.
+// Disable it to force HTTP/1.1 when proxies misbehave with HTTP/2.
+// It only adjusts a *http.Transport. Other http.RoundTrippers(e.g. a stub set by WithTransport()) are kept as is.
+func WithHTTP2(enabled bool) Option {
+	return func(c *Client) {
+		adjustTransport(c, func(t *http.Transport) {
+			t.ForceAttemptHTTP2 = enabled
+			if enabled {
+				t.TLSNextProto = nil
+			} else {
+				// A non-nil empty TLSNextProto map disables HTTP/2.
+				t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+				// Do not advertise HTTP/2 by ALPN either.
+				if t.TLSClientConfig != nil {
+					protos := []string{}
+					for _, proto := range t.TLSClientConfig.NextProtos {
+						if proto != "h2" {
+							protos = append(protos, proto)
+						}
+					}
+					t.TLSClientConfig.NextProtos = protos
+				}
+			}
+		})
+	}
+}
+
+// WithHTTPClient specifies the HTTP client to make requests. e.g. the client of httptest.Server.
+// Its transport, timeout, cookie jar and redirect policy are copied to the embedded http.Client.
+// It's useful to set proxy / TLS config centrally.
+// Options which adjust the transport should follow it. e.g. WithHTTP2().
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		if client != nil {
+			c.Client = *client
+		}
+	}
+}
+
+// WithTransport specifies the transport to make requests. e.g. a custom http.RoundTripper as a stub in tests.
+// http.DefaultTransport is used if no one specified.
+// Options which adjust the transport should follow it. e.g. WithHTTP2().
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.Transport = transport
+	}
+}
+
+// WithProxy routes requests through the HTTP proxy. e.g. "http://proxy.example.com:8080".
+// It overrides the proxy from environment variables(HTTP_PROXY, HTTPS_PROXY).
+// If the proxy URL is invalid, requests fail with the parse error.
+//
+// It adjusts the transport of the client. If WithHTTPClient() or WithTransport() are also given,
+// options are applied in order, so it should follow them. Otherwise the proxy is overridden by them.
+// It only adjusts a *http.Transport. Other http.RoundTrippers(e.g. a stub set by WithTransport()) are kept as is.
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) {
+		adjustTransport(c, func(t *http.Transport) {
+			u, err := url.Parse(proxyURL)
+			if err != nil {
+				t.Proxy = func(*http.Request) (*url.URL, error) { return nil, err }
+			} else {
+				t.Proxy = http.ProxyURL(u)
+			}
+		})
+	}
+}
+
+// WithMaxConcurrency limits the number of concurrent in-flight HTTP requests to aliyun.
+// It's useful to avoid overwhelming a shared egress. n <= 0 means no limit.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			c.sem = nil
+			return
+		}
+		c.sem = make(chan struct{}, n)
+	}
+}
+
+// WithNonceSource specifies the nonce source to generate "SignatureNonce" for each request.
+// It's UUIDNonceSource by default. e.g. use InstanceNonceSource() in multi-instance deployments.
+// A nil source is ignored and the current one is kept.
+func WithNonceSource(source NonceSource) Option {
+	return func(c *Client) {
+		if source != nil {
+			c.nonce = source
+		}
+	}
+}
+
+// WithUserAgent specifies the User-Agent header of requests. e.g. identify your service to aliyun support.
+// It's DefaultUserAgent if no one specified.
+func WithUserAgent(userAgent string) Option {
+	return WithUserAgents([]string{userAgent})
+}
+
+// WithUserAgents specifies the User-Agent headers used for requests in round-robin order.
+// It's useful when egress proxies rate-limit by User-Agent.
+// DefaultUserAgent is used if no one specified.
+func WithUserAgents(userAgents []string) Option {
+	return func(c *Client) {
+		c.userAgents = append([]string{}, userAgents...)
+	}
+}
+
+// WithResponseValidator specifies the validator to enforce custom acceptance criteria of responses.
+// It's invoked after the response is parsed.
+// If it returns an error, SendSMS(), MakeSingleCallByTTS()... return the error with the response.
+func WithResponseValidator(validator func(*Response) error) Option {
+	return func(c *Client) {
+		c.validator = validator
+	}
+}
+
+// WithRetry retries requests which are throttled or failed by server errors(HTTP 5xx).
+// e.g. "isv.BUSINESS_LIMIT_CONTROL", "Throttling.User". Other errors are returned immediately.
+//
+// maxAttempts: max number of attempts including the first one. maxAttempts <= 1 means no retry.
+// baseDelay: delay before the first retry. It doubles for each retry with jitter.
+//
+// The timestamp and the nonce are regenerated for each retry unless they're specified by params.
+// Use Idempotent() to retry network errors with the same nonce for at-most-once sends.
+// The delay is interrupted when the context is canceled or the client is closed by Close().
+// Use InFlightRetries() to get the number of requests in the delay.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.retry = retryPolicy{maxAttempts: maxAttempts, baseDelay: baseDelay}
+	}
+}
+
+// WithRoundTripHook specifies the hook invoked after each HTTP round trip(including retries) to aliyun.
+// e.g. emit latency metrics and log request IDs centrally.
+//
+// It's invoked even if the status code of the response is not "OK" or the body fails to parse.
+// resp is nil if err is a transport error. Otherwise the body of resp is already read and can be read again.
+// elapsed is the duration of the round trip including reading the body.
+func WithRoundTripHook(hook func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)) Option {
+	return func(c *Client) {
+		c.roundTripHook = hook
+	}
+}
+
+// WithLogger specifies the logger to log requests and responses at debug level.
+// Requests are logged with the action, phone count, signature method and canonicalized query string.
+// Responses are logged with the HTTP status code, status code and request ID.
+//
+// The access key secret is never logged. The access key ID and the security token are masked.
+// The signature is truncated. Nothing is logged by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithDefaultSignName specifies the signature name of SendSMS() if it's called with an empty one.
+// The signature name passed to SendSMS() always overrides it.
+func WithDefaultSignName(signName string) Option {
+	return func(c *Client) {
+		c.defaultSignName = signName
+	}
+}
+
+// WithDefaultTemplateCode specifies the template code of SendSMS() if it's called with an empty one.
+// The template code passed to SendSMS() always overrides it.
+func WithDefaultTemplateCode(templateCode string) Option {
+	return func(c *Client) {
+		c.defaultTemplateCode = templateCode
+	}
+}
+
+// WithClockSkew specifies the offset applied to generated timestamps.
+// e.g. -2 * time.Minute if the clock of the host is 2 minutes ahead.
+//
+// It also enables syncing with the server time when aliyun rejects the timestamp with InvalidTimeStamp.Expired.
+// The server time is read from the "Date" header of the response.
+// The rejected request is retried once with the server time even if it's not idempotent or the retry policy is exhausted,
+// and the server time offset is used for later timestamps instead of the specified offset.
+// Timestamps specified by Timestamp() are not adjusted, and the request is not retried if they're expired.
+//
+// Syncing the clock of the host by NTP is the preferred fix. It's only a safety net.
+func WithClockSkew(offset time.Duration) Option {
+	return func(c *Client) {
+		c.clockSkew = offset
+		c.clockSync = true
+	}
+}
+
+// WithSignatureFallback specifies the preferred signature method: "HMAC-SHA1" or "HMAC-SHA256".
+// Requests are signed with it unless SignatureMethod() is specified by params.
+//
+// If aliyun rejects the signature method with InvalidSignatureMethod, the request is retried once signed with the other method
+// even if the retry policy is exhausted, and the working method is used for later requests of the client.
+// It's for accounts which differ in the accepted signature methods.
+// Other methods are ignored.
+func WithSignatureFallback(preferred string) Option {
+	return func(c *Client) {
+		switch m := strings.ToUpper(preferred); m {
+		case pop.HMACSHA1, pop.HMACSHA256:
+			c.signatureFallback = true
+			c.signatureMethod.Store(m)
+		}
+	}
+}
+
+// adjustTransport adjusts a copy of the transport of the client by f if it's nil(http.DefaultTransport) or a *http.Transport.
+// Other http.RoundTrippers are kept as is, so requests are never sent by a replaced transport.
+func adjustTransport(c *Client, f func(t *http.Transport)) {
+	var t *http.Transport
+	switch rt := c.Transport.(type) {
+	case nil:
+		t = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		t = rt.Clone()
+	default:
+		return
+	}
+	f(t)
+	c.Transport = t
+}
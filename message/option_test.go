@@ -0,0 +1,572 @@
+package message_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/testhelper"
+)
+
+func TestWithHTTP2(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	tests := []struct {
+		enabled bool
+		proto   string
+	}{
+		{true, "HTTP/2.0"},
+		{false, "HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		client := message.NewClient("my_key_id", "my_key_secret")
+		// Trust the certificate of the stub server.
+		client.Transport = srv.Client().Transport
+		message.WithHTTP2(tt.enabled)(client)
+
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Errorf("Get() with HTTP/2 enabled: %v error: %v", tt.enabled, err)
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.Proto != tt.proto {
+			t.Errorf("negotiated protocol with HTTP/2 enabled: %v = %v, want %v", tt.enabled, resp.Proto, tt.proto)
+		}
+	}
+}
+
+func TestWithHTTP2CustomTransport(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		requests := 0
+		transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK"}`), nil
+		})
+
+		// The custom transport is kept.
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithTransport(transport), message.WithHTTP2(enabled))
+		if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+			t.Fatalf("SendSMS() with HTTP/2 enabled: %v = %v, %v, want true, nil", enabled, ok, err)
+		}
+		if requests != 1 {
+			t.Errorf("requests of the custom transport with HTTP/2 enabled: %v = %v, want 1", enabled, requests)
+		}
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK","BizId":"134523^4351232"}`)
+	}))
+	defer srv.Close()
+
+	httpClient := srv.Client()
+	httpClient.Timeout = 5 * time.Second
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithHTTPClient(httpClient))
+	if client.Timeout != httpClient.Timeout {
+		t.Errorf("Timeout = %v, want %v", client.Timeout, httpClient.Timeout)
+	}
+
+	ok, resp, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.Endpoint(srv.Listener.Addr().String()))
+	if !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want true, nil", ok, err)
+	}
+	if resp.BizID != "134523^4351232" {
+		t.Errorf("BizID = %v, want 134523^4351232", resp.BizID)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	host := ""
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		host = req.URL.Host
+		return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK"}`), nil
+	})
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithTransport(transport))
+
+	if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want true, nil", ok, err)
+	}
+	if host != "dysmsapi.aliyuncs.com" {
+		t.Errorf("host = %v, want dysmsapi.aliyuncs.com", host)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	host := ""
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The proxy receives the absolute URL of the request.
+		host = r.URL.Host
+		fmt.Fprint(w, `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK"}`)
+	}))
+	defer proxy.Close()
+
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithHTTPClient(&http.Client{}), message.WithProxy(proxy.URL))
+	ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.Scheme("http"))
+	if !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want true, nil", ok, err)
+	}
+	if host != "dysmsapi.aliyuncs.com" {
+		t.Errorf("proxied host = %v, want dysmsapi.aliyuncs.com", host)
+	}
+
+	// The custom transport is kept.
+	requests := 0
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK"}`), nil
+	})
+	client = message.NewClient("my_key_id", "my_key_secret", message.WithTransport(transport), message.WithProxy(proxy.URL))
+	if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+		t.Fatalf("SendSMS() with WithTransport() and WithProxy() = %v, %v, want true, nil", ok, err)
+	}
+	if requests != 1 {
+		t.Errorf("requests of the custom transport = %v, want 1", requests)
+	}
+
+	// Invalid proxy URL.
+	client = message.NewClient("my_key_id", "my_key_secret", message.WithProxy("http://[::1"))
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err == nil {
+		t.Errorf("SendSMS() error = nil, want error of the invalid proxy URL")
+	}
+}
+
+func TestWithMaxConcurrency(t *testing.T) {
+	const n = 2
+	var inFlight, maxInFlight int32
+
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithMaxConcurrency(n))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+				break
+			}
+		}
+
+		// Block for a while to make requests overlap.
+		time.Sleep(20 * time.Millisecond)
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+				t.Errorf("SendSMS() error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > n {
+		t.Errorf("max in-flight requests = %v, want <= %v", maxInFlight, n)
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	tests := []struct {
+		options []message.Option
+		want    string
+	}{
+		{nil, message.DefaultUserAgent},
+		{[]message.Option{message.WithUserAgent("my-service/2.0")}, "my-service/2.0"},
+	}
+
+	for _, tt := range tests {
+		client := message.NewClient("my_key_id", "my_key_secret", tt.options...)
+
+		// SMS and voice requests.
+		for _, action := range []string{"SendSms", "SingleCallByTts"} {
+			req, err := client.BuildRequest(context.Background(), action, nil)
+			if err != nil {
+				t.Fatalf("BuildRequest() error: %v", err)
+			}
+			if got := req.Header.Get("User-Agent"); got != tt.want {
+				t.Errorf("User-Agent of %v = %v, want %v", action, got, tt.want)
+			}
+		}
+
+		if config := client.DebugConfig(); !strings.Contains(config, "userAgent="+tt.want) {
+			t.Errorf("DebugConfig() = %v, want it contains userAgent=%v", config, tt.want)
+		}
+	}
+}
+
+func TestWithUserAgents(t *testing.T) {
+	userAgents := []string{"agent-a", "agent-b", "agent-c"}
+	got := []string{}
+
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithUserAgents(userAgents))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = append(got, req.Header.Get("User-Agent"))
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	for i := 0; i < 4; i++ {
+		if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+	}
+
+	want := []string{"agent-a", "agent-b", "agent-c", "agent-a"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("User-Agent headers = %v, want %v", got, want)
+	}
+}
+
+func TestWithResponseValidator(t *testing.T) {
+	errSoftFailure := errors.New("soft failure")
+	validator := func(r *message.Response) error {
+		if strings.HasPrefix(r.RequestID, "SOFT-") {
+			return errSoftFailure
+		}
+		return nil
+	}
+
+	tests := []struct {
+		body string
+		ok   bool
+		err  error
+	}{
+		{`{"RequestId":"8906582E-6722","Code":"OK","BizId":"134523^4351232"}`, true, nil},
+		{`{"RequestId":"SOFT-6722","Code":"OK","BizId":"134523^4351232"}`, false, errSoftFailure},
+	}
+
+	for _, tt := range tests {
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithResponseValidator(validator))
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+		if ok != tt.ok || err != tt.err {
+			t.Errorf("SendSMS() = %v, %v, want %v, %v", ok, err, tt.ok, tt.err)
+		}
+		if resp == nil || resp.BizID != "134523^4351232" {
+			t.Errorf("SendSMS() response = %v, want parsed response", resp)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	serverError := testhelper.Response{StatusCode: http.StatusServiceUnavailable, Body: "Service Unavailable"}
+	illegal := testhelper.Response{StatusCode: http.StatusOK, Body: `{"RequestId":"STUB-ILLEGAL","Code":"isv.MOBILE_NUMBER_ILLEGAL","Message":"illegal"}`}
+
+	tests := []struct {
+		responses []testhelper.Response
+		ok        bool
+		requests  int
+	}{
+		// Retry on throttling to success.
+		{[]testhelper.Response{testhelper.Throttled, testhelper.Throttled, testhelper.OK}, true, 3},
+		// Retry on server errors to success.
+		{[]testhelper.Response{serverError, testhelper.OK}, true, 2},
+		// Give up after max attempts.
+		{[]testhelper.Response{testhelper.Throttled}, false, 3},
+		// Non-retryable codes fail immediately.
+		{[]testhelper.Response{illegal, testhelper.OK}, false, 1},
+	}
+
+	for _, tt := range tests {
+		transport := testhelper.NewSequenceTransport(tt.responses...)
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(3, time.Millisecond))
+		client.Transport = transport
+
+		ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+		if ok != tt.ok || (err == nil) != tt.ok {
+			t.Errorf("SendSMS() = %v, %v, want ok: %v", ok, err, tt.ok)
+		}
+
+		requests := transport.Requests()
+		if len(requests) != tt.requests {
+			t.Errorf("requests = %v, want %v", len(requests), tt.requests)
+		}
+
+		// Nonce and timestamp are regenerated for each attempt.
+		nonces := map[string]bool{}
+		for _, req := range requests {
+			nonces[req.URL.Query().Get("SignatureNonce")] = true
+		}
+		if len(nonces) != len(requests) {
+			t.Errorf("nonces = %v, want %v different nonces", nonces, len(requests))
+		}
+	}
+}
+
+func TestWithRetryFixedNonce(t *testing.T) {
+	transport := testhelper.NewSequenceTransport(testhelper.Throttled, testhelper.OK)
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(3, time.Millisecond))
+	client.Transport = transport
+
+	ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.SignatureNonce("my_nonce"))
+	if !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want true, nil", ok, err)
+	}
+
+	for _, req := range transport.Requests() {
+		if nonce := req.URL.Query().Get("SignatureNonce"); nonce != "my_nonce" {
+			t.Errorf("SignatureNonce = %v, want my_nonce", nonce)
+		}
+	}
+}
+
+func TestWithRetryContextCanceled(t *testing.T) {
+	transport := testhelper.NewSequenceTransport(testhelper.Throttled)
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(3, time.Hour))
+	client.Transport = transport
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err := client.SendSMSContext(ctx, []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendSMSContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := len(transport.Requests()); n != 1 {
+		t.Errorf("requests = %v, want 1", n)
+	}
+}
+
+func TestInFlightRetriesClose(t *testing.T) {
+	transport := testhelper.NewSequenceTransport(testhelper.Throttled)
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(3, time.Hour))
+	client.Transport = transport
+
+	const n = 3
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+			errs <- err
+		}()
+	}
+
+	// Wait for all requests to sleep in the backoff.
+	deadline := time.Now().Add(5 * time.Second)
+	for client.InFlightRetries() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("InFlightRetries() = %v, want %v", client.InFlightRetries(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Close cancels the backoff sleeps promptly.
+	start := time.Now()
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errs; !errors.Is(err, message.ErrClientClosed) {
+			t.Errorf("SendSMS() error = %v, want %v", err, message.ErrClientClosed)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("requests return %v after Close(), want promptly", elapsed)
+	}
+	if got := client.InFlightRetries(); got != 0 {
+		t.Errorf("InFlightRetries() after Close() = %v, want 0", got)
+	}
+	if got := len(transport.Requests()); got != n {
+		t.Errorf("requests = %v, want %v", got, n)
+	}
+
+	// Close is idempotent.
+	if err := client.Close(); err != nil {
+		t.Errorf("Close() twice error: %v", err)
+	}
+}
+
+func TestWithRoundTripHook(t *testing.T) {
+	errTransport := errors.New("connection reset")
+	tests := []struct {
+		resp *http.Response
+		err  error
+	}{
+		{newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"OK"}`), nil},
+		{newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"isv.MOBILE_NUMBER_ILLEGAL"}`), nil},
+		{newStubResponse(http.StatusBadGateway, `<html>Bad Gateway</html>`), nil},
+		{nil, errTransport},
+	}
+
+	for _, tt := range tests {
+		calls := 0
+		body := ""
+		var hookErr error
+		hook := func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+			calls++
+			hookErr = err
+			if req.URL.Query().Get("Action") != "SendSms" {
+				t.Errorf("hook request Action = %v, want SendSms", req.URL.Query().Get("Action"))
+			}
+			if resp != nil {
+				buf, _ := ioutil.ReadAll(resp.Body)
+				body = string(buf)
+			}
+		}
+
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithRoundTripHook(hook))
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if tt.err != nil {
+				return nil, tt.err
+			}
+			return tt.resp, nil
+		})
+
+		client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+		if calls != 1 {
+			t.Errorf("hook calls = %v, want 1", calls)
+		}
+		if tt.err != nil && !errors.Is(hookErr, tt.err) {
+			t.Errorf("hook error = %v, want %v", hookErr, tt.err)
+		}
+		if tt.resp != nil && body == "" {
+			t.Errorf("hook can not read the body of the response")
+		}
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var req *http.Request
+	client := message.NewClient("LTAI_my_key_id", "my_key_secret", message.WithLogger(logger))
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000", "13900139000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.SecurityToken("my_security_token")); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+
+	logs := buf.String()
+	for _, want := range []string{"action=SendSms", "phoneCount=2", "signatureMethod=HMAC-SHA1", "AccessKeyId=LTAI%2A%2A%2A%2A", "code=OK", "requestId=8906582E-6722", "httpStatusCode=200"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("logs = %v, want it contains %v", logs, want)
+		}
+	}
+
+	_, signature := sentParams(req)
+	for _, secret := range []string{"my_key_secret", "LTAI_my_key_id", "my_security_token", signature, url.QueryEscape(signature)} {
+		if strings.Contains(logs, secret) {
+			t.Errorf("logs = %v, want it does not contain %v", logs, secret)
+		}
+	}
+}
+
+func TestWithLoggerDefault(t *testing.T) {
+	buf := &bytes.Buffer{}
+	// Info level discards debug logs.
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithLogger(logger))
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("logs = %v, want nothing at info level", buf.String())
+	}
+}
+
+func TestWithDefaultSignNameAndTemplateCode(t *testing.T) {
+	tests := []struct {
+		signName         string
+		templateCode     string
+		wantSignName     string
+		wantTemplateCode string
+	}{
+		// Defaults are used for empty values.
+		{"", "", "default_product", "SMS_DEFAULT"},
+		{"", "SMS_0000", "default_product", "SMS_0000"},
+		{"my_product", "", "my_product", "SMS_DEFAULT"},
+		// Explicit values override defaults.
+		{"my_product", "SMS_0000", "my_product", "SMS_0000"},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret",
+			message.WithDefaultSignName("default_product"),
+			message.WithDefaultTemplateCode("SMS_DEFAULT"),
+		)
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		if _, _, err := client.SendSMS([]string{"13800138000"}, tt.signName, tt.templateCode, `{"code":"1234"}`); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+		if query.Get("SignName") != tt.wantSignName || query.Get("TemplateCode") != tt.wantTemplateCode {
+			t.Errorf("SendSMS(%q, %q): SignName = %v, TemplateCode = %v, want %v, %v",
+				tt.signName, tt.templateCode, query.Get("SignName"), query.Get("TemplateCode"), tt.wantSignName, tt.wantTemplateCode)
+		}
+	}
+}
+
+func TestWithSignatureFallback(t *testing.T) {
+	var methods []string
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithSignatureFallback("HMAC-SHA256"))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		m := req.URL.Query().Get("SignatureMethod")
+		methods = append(methods, m)
+		if m == "HMAC-SHA256" {
+			return newStubResponse(http.StatusBadRequest, `{"RequestId":"STUB","Code":"InvalidSignatureMethod","Message":"Specified signature method is not supported."}`), nil
+		}
+		return newStubResponse(http.StatusOK, `{"RequestId":"STUB","Code":"OK","Message":"OK"}`), nil
+	})
+
+	// SHA256 is rejected and SHA1 succeeds.
+	if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want OK", ok, err)
+	}
+	// SHA1 is cached for later requests.
+	if ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); !ok || err != nil {
+		t.Fatalf("SendSMS() = %v, %v, want OK", ok, err)
+	}
+	want := []string{"HMAC-SHA256", "HMAC-SHA1", "HMAC-SHA1"}
+	if strings.Join(methods, ",") != strings.Join(want, ",") {
+		t.Errorf("signature methods = %v, want %v", methods, want)
+	}
+
+	// The method specified by params never falls back.
+	methods = nil
+	_, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.SignatureMethod("HMAC-SHA256"))
+	var apiErr *message.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != message.CodeInvalidSignatureMethod {
+		t.Errorf("SendSMS() error = %v, want InvalidSignatureMethod", err)
+	}
+	if len(methods) != 1 {
+		t.Errorf("signature methods = %v, want HMAC-SHA256 only", methods)
+	}
+}
@@ -1,31 +1,126 @@
 package message
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
+
+	"github.com/northbright/aliyun/internal/pop"
 )
 
 // Param is the parameter for HTTP request of aliyun API.
 // Use param helper functions to get specified Param. e.g. Timestamp(), SignatureNonce().
 type Param struct {
+	// f sets the parameter in the query which is sent to aliyun.
 	f func(v url.Values)
+	// o sets the local option of the request which is not sent to aliyun.
+	o func(o *requestOptions)
+	// err is the error of an invalid parameter.
+	// The request is not sent if any parameter is invalid.
+	err error
+}
+
+// requestOptions are the local options of a request which are not sent to aliyun.
+type requestOptions struct {
+	// clientRequestID is the caller-provided request ID for tracing.
+	clientRequestID string
+	// scheme is the scheme of the request URL.
+	scheme string
+	// endpoint is the host of the request URL.
+	endpoint string
+	// method is the HTTP method of the request. It's "GET" if it's empty.
+	method string
+	// fixedTimestamp is true if the timestamp is specified by the caller.
+	// It's not regenerated on retries.
+	fixedTimestamp bool
+	// fixedNonce is true if the nonce is specified by the caller.
+	// It's not regenerated on retries.
+	fixedNonce bool
+	// fixedSignatureMethod is true if the signature method is specified by the caller.
+	// It's not replaced by WithSignatureFallback().
+	fixedSignatureMethod bool
+	// idempotent is true if the request is sent by Idempotent().
+	// Network errors are retried with the same nonce.
+	idempotent bool
+	// validateTemplateParam is true if the template params are validated before sending.
+	validateTemplateParam bool
+	// timeout is the timeout of the request including retries if it's greater than 0.
+	timeout time.Duration
+	// groupCallConcurrency is the max number of concurrent calls of MakeVoiceGroupCall() if it's greater than 0.
+	groupCallConcurrency int
+	// signedURL receives the signed URL of each attempt which is sent if it's not nil.
+	signedURL *string
+}
+
+// clientRequestIDKey is the context key of the client request ID.
+type clientRequestIDKey struct{}
+
+// applyParams sets parameters in the query and returns the local options of the request.
+// It returns the error of the first invalid parameter if any.
+func applyParams(v url.Values, params []Param) (*requestOptions, error) {
+	o := &requestOptions{}
+	for _, param := range params {
+		if param.err != nil {
+			return nil, param.err
+		}
+		if param.f != nil {
+			param.f(v)
+		}
+		if param.o != nil {
+			param.o(o)
+		}
+	}
+
+	if o.validateTemplateParam {
+		for _, key := range []string{"TemplateParam", "TtsParam"} {
+			// Templates without variables are sent with an empty param.
+			if value, ok := v[key]; ok && value[0] != "" && !json.Valid([]byte(value[0])) {
+				return nil, fmt.Errorf("invalid %s JSON: %q", key, value[0])
+			}
+		}
+	}
+	return o, nil
 }
 
 // Timestamp specifies the timestamp.
 // aliyun requires GMT but not local time.
 // It will generate timestamp automatically by default if no one specifed.
+// A specified timestamp is kept on retries.
 func Timestamp(t time.Time) Param {
-	return Param{f: func(v url.Values) {
-		v.Set("Timestamp", GenTimestamp(t))
-	}}
+	return Param{
+		f: func(v url.Values) { v.Set("Timestamp", GenTimestamp(t)) },
+		o: func(o *requestOptions) { o.fixedTimestamp = true },
+	}
 }
 
-// SignatureMethod specifies the signature method.
+// Format specifies the format of the response: "JSON" or "XML".
+// It's "JSON" by default if no one specified.
+// The "Accept" header of the HTTP request is set to match the format.
+func Format(f string) Param {
+	return Param{f: func(v url.Values) { v.Set("Format", f) }}
+}
+
+// SignatureMethod specifies the signature method: "HMAC-SHA1" or "HMAC-SHA256".
 // It's "HMAC-SHA1" by default if no one specifed.
+// It overrides the method of WithSignatureFallback() and is never replaced by the fallback.
+// The method is case-insensitive and sent in the canonical upper case. e.g. "hmac-sha256" -> "HMAC-SHA256".
+// The request is not sent for other signature methods.
 func SignatureMethod(m string) Param {
-	return Param{f: func(v url.Values) { v.Set("SignatureMethod", m) }}
+	switch m := strings.ToUpper(m); m {
+	case pop.HMACSHA1, pop.HMACSHA256:
+		return Param{
+			f: func(v url.Values) { v.Set("SignatureMethod", m) },
+			o: func(o *requestOptions) { o.fixedSignatureMethod = true },
+		}
+	}
+	return Param{err: fmt.Errorf("unsupported SignatureMethod: %q, want HMAC-SHA1 or HMAC-SHA256", m)}
 }
 
 // SignatureVersion specifies the signature version.
@@ -36,8 +131,46 @@ func SignatureVersion(ver string) Param {
 
 // SignatureNonce specifies the nonce.
 // It will generate UUID as nonce automatically by default if no one specified.
+// Params are applied after the generated nonce, so a specified nonce always overrides it and is kept on retries.
+// Specify it with Timestamp() to get the deterministic signature. e.g. snapshot tests.
 func SignatureNonce(nonce string) Param {
-	return Param{f: func(v url.Values) { v.Set("SignatureNonce", nonce) }}
+	return Param{
+		f: func(v url.Values) { v.Set("SignatureNonce", nonce) },
+		o: func(o *requestOptions) { o.fixedNonce = true },
+	}
+}
+
+// Idempotent specifies the nonce and the out ID for at-most-once sends.
+// They're kept on retries of WithRetry(), so aliyun can deduplicate the request by the nonce.
+// The nonce should be unique for each message. e.g. a UUID stored with the message.
+//
+// Besides throttled requests and server errors, it also retries:
+//
+// - Network errors with the same nonce, since the request may not reach aliyun.
+// - "InvalidTimeStamp.Expired" errors with a regenerated timestamp and nonce, since the request is rejected by aliyun.
+// The timestamp specified by Timestamp() is never regenerated, so its expiry is not retried.
+//
+// If the request of a network error reached aliyun and only the response is lost,
+// the retry with the same nonce fails with an *APIError of CodeSignatureNonceUsed although the SMS was sent.
+// Treat the error as "maybe delivered" and check it by QuerySendDetails() with the out ID instead of sending again.
+func Idempotent(nonce, outID string) Param {
+	return Param{
+		f: func(v url.Values) {
+			v.Set("SignatureNonce", nonce)
+			v.Set("OutId", outID)
+		},
+		o: func(o *requestOptions) {
+			o.fixedNonce = true
+			o.idempotent = true
+		},
+	}
+}
+
+// SecurityToken specifies the STS security token of temporary credentials.
+// It's signed with other parameters.
+// It's overridden by the security token of the CredentialProvider if it's not empty.
+func SecurityToken(token string) Param {
+	return Param{f: func(v url.Values) { v.Set("SecurityToken", token) }}
 }
 
 // Action specifies the action.
@@ -54,27 +187,75 @@ func Version(ver string) Param {
 
 // RegionID specifies the region ID.
 // It's "cn-hangzhou" by default if no one specified.
+// SMS requests are sent to the regional endpoint for other regions.
+// e.g. "dysmsapi.ap-southeast-1.aliyuncs.com" for "ap-southeast-1".
 func RegionID(ID string) Param {
 	return Param{f: func(v url.Values) { v.Set("RegionId", ID) }}
 }
 
-// OutID specifies the caller's out ID.
+// OutID specifies the caller's out ID. e.g. the internal message ID to correlate receipts.
+// It's echoed in OutID of the response if aliyun returns it.
 func OutID(ID string) Param {
 	return Param{f: func(v url.Values) { v.Set("OutId", ID) }}
 }
 
+// SignName specifies the signature name for actions which do not take it as an argument.
+// e.g. SendCardSms().
+func SignName(name string) Param {
+	return Param{f: func(v url.Values) { v.Set("SignName", name) }}
+}
+
+// SmsUpExtendCode specifies the extend code of SMS.
+// It's appended to the sender number as the extension.
+// Upstream SMS replied by users carry the extend code,
+// so it can be used to route replies back to the originating campaign.
+// The code must contain digits only. The request is not sent if it's invalid.
+func SmsUpExtendCode(code string) Param {
+	if !isDigits(code) {
+		return Param{err: fmt.Errorf("invalid SmsUpExtendCode: %q, digits only", code)}
+	}
+	return Param{f: func(v url.Values) { v.Set("SmsUpExtendCode", code) }}
+}
+
 // Volume specifies the call volumn.
-// Range: 0 - 200. It's 100 by default if no one specified.
+// Range: 0 - 100. It's 100 by default if no one specified.
+// It only applies to voice calls and it's ignored for SMS.
 func Volume(volume int) Param {
 	return Param{f: func(v url.Values) { v.Set("Volume", strconv.Itoa(volume)) }}
 }
 
 // PlayTimes specifies the play times of the voice message.
-// It's 1 by default if no one specified.
+// Range: 1 - 3. It's 1 by default if no one specified.
+// e.g. play the verification code twice.
+// It only applies to voice calls and it's ignored for SMS.
 func PlayTimes(n int) Param {
 	return Param{f: func(v url.Values) { v.Set("PlayTimes", strconv.Itoa(n)) }}
 }
 
+// Speed specifies the speech speed of TTS.
+// Range: -500 - 500. It's 0 by default if no one specified.
+// It only applies to voice calls and it's ignored for SMS.
+func Speed(s int) Param {
+	return Param{f: func(v url.Values) { v.Set("Speed", strconv.Itoa(s)) }}
+}
+
+// GroupCallConcurrency specifies the max number of concurrent calls of MakeVoiceGroupCall().
+// It's 5 by default if no one specified or n is less than 1.
+// It's not sent to aliyun and it's ignored by other requests.
+func GroupCallConcurrency(n int) Param {
+	return Param{o: func(o *requestOptions) { o.groupCallConcurrency = n }}
+}
+
+// voiceParams are parameters which only apply to voice calls.
+var voiceParams = []string{"PlayTimes", "Volume", "Speed"}
+
+// ignoreVoiceParams removes the parameters which only apply to voice calls for SMS actions.
+func ignoreVoiceParams(v url.Values) {
+	for _, key := range voiceParams {
+		v.Del(key)
+	}
+}
+
 // PhoneNumbers specifies the phone numbers to send SMS.
 func PhoneNumbers(nums []string) Param {
 	return Param{f: func(v url.Values) {
@@ -82,30 +263,236 @@ func PhoneNumbers(nums []string) Param {
 	}}
 }
 
+// TrimTemplateParam trims trailing whitespace(e.g. "\n" of template params read from files)
+// of "TemplateParam" and "TtsParam" before signing.
+// Template params are sent and signed as is by default.
+// Trimming changes the content sent to aliyun, so it's not enabled by default.
+func TrimTemplateParam() Param {
+	return Param{f: func(v url.Values) {
+		for _, key := range []string{"TemplateParam", "TtsParam"} {
+			if _, ok := v[key]; ok {
+				v.Set(key, strings.TrimRight(v.Get(key), " \t\r\n"))
+			}
+		}
+	}}
+}
+
+// ValidateTemplateParam validates "TemplateParam" and "TtsParam" are well-formed JSON
+// after all other params are applied.
+// Empty ones are skipped for templates without variables.
+// The request is not sent if they're invalid.
+// It's useful when template params are built by string concatenation. Use TemplateParam() to avoid it.
+func ValidateTemplateParam() Param {
+	return Param{o: func(o *requestOptions) { o.validateTemplateParam = true }}
+}
+
+// Scheme specifies the scheme of the request URL: "https" or "http".
+// It's "https" by default if no one specified.
+// The scheme is not signed, so switching it does not change the signature.
+func Scheme(s string) Param {
+	return Param{o: func(o *requestOptions) { o.scheme = s }}
+}
+
+// Endpoint specifies the host of the request URL. e.g. "dysmsapi.ap-southeast-1.aliyuncs.com".
+// It overrides the host resolved from the region ID by Endpoints. Regions which are not in Endpoints are rejected without it.
+// Use EndpointForService() to get the endpoint of a service in a region, or EndpointForRegion() for other regions.
+func Endpoint(host string) Param {
+	return Param{o: func(o *requestOptions) { o.endpoint = host }}
+}
+
+// Method specifies the HTTP method of the request: "GET" or "POST".
+// It's "GET" by default if no one specified.
+// The parameters are sent in the form body for "POST", which avoids URL length limits.
+// e.g. large TemplateParamJson of SendBatchSMS().
+// The canonicalized query string is the same but signed with the method.
+func Method(method string) Param {
+	method = strings.ToUpper(method)
+	if method != http.MethodGet && method != http.MethodPost {
+		return Param{err: fmt.Errorf("invalid method: %q, GET or POST only", method)}
+	}
+	return Param{o: func(o *requestOptions) { o.method = method }}
+}
+
+// Timeout specifies the timeout of the request including retries.
+// It applies a context deadline to this request only but does not change the Timeout of the shared http.Client.
+// The effective deadline is the earlier one of the timeout and the deadline of the context.
+// e.g. verification code sends should time out faster than bulk sends.
+func Timeout(d time.Duration) Param {
+	return Param{o: func(o *requestOptions) { o.timeout = d }}
+}
+
+// ClientRequestID specifies the caller-provided request ID for end-to-end tracing.
+// It's not sent to aliyun and it's different from the request ID in the response.
+// It's stored on the context of the HTTP request.
+// Use ClientRequestIDFromContext() to get it in transports, hooks...
+func ClientRequestID(ID string) Param {
+	return Param{o: func(o *requestOptions) { o.clientRequestID = ID }}
+}
+
+// ClientRequestIDFromContext returns the client request ID stored on the context of the HTTP request.
+// It returns an empty string if no one found.
+func ClientRequestIDFromContext(ctx context.Context) string {
+	ID, _ := ctx.Value(clientRequestIDKey{}).(string)
+	return ID
+}
+
+// isDigits checks if the string is not empty and contains digits only.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // GenTimestamp generates the timestamp for aliyun services.
 // aliyun requires GMT but not local time.
 func GenTimestamp(t time.Time) string {
-	gmt := t.UTC()
-	return fmt.Sprintf("%04d-%02d-%02dT%02d:%02d:%02dZ",
-		gmt.Year(),
-		gmt.Month(),
-		gmt.Day(),
-		gmt.Hour(),
-		gmt.Minute(),
-		gmt.Second(),
-	)
+	return pop.Timestamp(t)
+}
+
+// sendDateZone is the time zone of the "SendDate" parameter of query APIs. It's China Standard Time(UTC+8).
+var sendDateZone = time.FixedZone("CST", 8*60*60)
+
+// GenSendDate generates the "SendDate" parameter for query APIs. e.g. QuerySendDetails().
+// aliyun requires the date in China Standard Time(UTC+8) in "yyyyMMdd" format. e.g. "20180101".
+func GenSendDate(t time.Time) string {
+	d := t.In(sendDateZone)
+	return fmt.Sprintf("%04d%02d%02d", d.Year(), d.Month(), d.Day())
+}
+
+// GenSendDateRange generates the "SendDate" parameters of the days from start to end(inclusive).
+// e.g. ["20181231", "20190101"] for the last day of 2018 to the first day of 2019.
+// It's empty if end is before start.
+func GenSendDateRange(start, end time.Time) []string {
+	s := start.In(sendDateZone)
+	e := end.In(sendDateZone)
+	day := time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, sendDateZone)
+	last := time.Date(e.Year(), e.Month(), e.Day(), 0, 0, 0, 0, sendDateZone)
+
+	dates := []string{}
+	for ; !day.After(last); day = day.AddDate(0, 0, 1) {
+		dates = append(dates, GenSendDate(day))
+	}
+	return dates
+}
+
+// MaxPhoneNumbersPerSend is the max number of phone numbers of SendSMS() in one request limited by aliyun.
+const MaxPhoneNumbersPerSend = 1000
+
+// ChunkPhoneNumbers splits the phone numbers into chunks of the size in order.
+// The last chunk has the rest of phone numbers.
+// The size is MaxPhoneNumbersPerSend if it's less than 1.
+// e.g. split the phone numbers to send by SendMany().
+func ChunkPhoneNumbers(nums []string, size int) [][]string {
+	if size < 1 {
+		size = MaxPhoneNumbersPerSend
+	}
+
+	chunks := make([][]string, 0, (len(nums)+size-1)/size)
+	for start := 0; start < len(nums); start += size {
+		end := start + size
+		if end > len(nums) {
+			end = len(nums)
+		}
+		chunks = append(chunks, nums[start:end:end])
+	}
+	return chunks
 }
 
+// ErrNoPhoneNumbers is returned by SendSMS() if there's no phone number or all of them are blank.
+// The request is not sent.
+var ErrNoPhoneNumbers = errors.New("no phone numbers")
+
 // GenPhoneNumbersStr generates the parameter string for one or more phone numbers.
 // Delimeter is ",".
+// Blank entries are skipped to avoid stray commas which aliyun rejects.
+// e.g. []string{"13800138000", ""} -> "13800138000".
 func GenPhoneNumbersStr(nums []string) string {
-	str := ""
-	l := len(nums)
+	filtered := make([]string, 0, len(nums))
+	for _, num := range nums {
+		if strings.TrimSpace(num) != "" {
+			filtered = append(filtered, num)
+		}
+	}
+	return strings.Join(filtered, ",")
+}
+
+// NormalizePhoneNumbers strips whitespace of the phone numbers and validates them.
+// Mainland China numbers are normalized to 11 digits without the country code.
+// e.g. "+86 138 0013 8000", "0086-13800138000" -> "13800138000".
+// International numbers must start with "+" or "00" followed by the country code.
+// They're normalized to the format of aliyun: country code + number. e.g. "+852 0000 0000" -> "85200000000".
+//
+// It returns the error identifying the first invalid entry. e.g. empty string.
+func NormalizePhoneNumbers(nums []string) ([]string, error) {
+	normalized := make([]string, 0, len(nums))
 	for i, num := range nums {
-		str += num
-		if i != l-1 {
-			str += ","
+		n, ok := normalizePhoneNumber(num)
+		if !ok {
+			return nil, fmt.Errorf("invalid phone number at %d: %q", i, num)
 		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
+
+// GenNormalizedPhoneNumbersStr normalizes the phone numbers by NormalizePhoneNumbers()
+// and generates the parameter string for them.
+func GenNormalizedPhoneNumbersStr(nums []string) (string, error) {
+	normalized, err := NormalizePhoneNumbers(nums)
+	if err != nil {
+		return "", err
+	}
+	return GenPhoneNumbersStr(normalized), nil
+}
+
+// normalizePhoneNumber normalizes one phone number and reports if it's plausible.
+func normalizePhoneNumber(num string) (string, bool) {
+	// Strip whitespace and "-" separators.
+	num = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return r
+	}, num)
+
+	international := false
+	switch {
+	case strings.HasPrefix(num, "+"):
+		num, international = num[1:], true
+	case strings.HasPrefix(num, "00"):
+		num, international = num[2:], true
+	}
+
+	if !isDigits(num) {
+		return "", false
+	}
+
+	if international {
+		if strings.HasPrefix(num, "86") {
+			num = num[2:]
+		} else {
+			// E.164 allows at most 15 digits including the country code.
+			return num, len(num) >= 8 && len(num) <= 15
+		}
+	}
+
+	// Mainland China mobile numbers: 11 digits starting with "1".
+	return num, len(num) == 11 && num[0] == '1'
+}
+
+// TemplateParam generates the JSON string of the template params to avoid manual escaping.
+// e.g. map[string]string{"code": "1234"} -> `{"code":"1234"}`.
+// It can be used as templateParam of SendSMS() or ttsParam of MakeSingleCallByTTS().
+func TemplateParam(m map[string]string) (string, error) {
+	buf, err := json.Marshal(m)
+	if err != nil {
+		return "", err
 	}
-	return str
+	return string(buf), nil
 }
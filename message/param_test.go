@@ -0,0 +1,782 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestSmsUpExtendCode(t *testing.T) {
+	var query url.Values
+	requests := 0
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.SmsUpExtendCode("1001")); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+
+	if got := query["SmsUpExtendCode"]; len(got) != 1 || got[0] != "1001" {
+		t.Errorf("SmsUpExtendCode in query = %v, want [1001]", got)
+	}
+	if query.Get("Signature") == "" {
+		t.Errorf("no signature in query")
+	}
+
+	// Invalid codes are rejected before sending.
+	for _, code := range []string{"", "10a1", "+1001", "10 01"} {
+		if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.SmsUpExtendCode(code)); err == nil {
+			t.Errorf("SendSMS() with SmsUpExtendCode(%q) returns no error", code)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("requests = %v, want 1", requests)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		params []message.Param
+		host   string
+	}{
+		{nil, "dysmsapi.aliyuncs.com"},
+		{[]message.Param{message.RegionID("cn-hangzhou")}, "dysmsapi.aliyuncs.com"},
+		{[]message.Param{message.RegionID("ap-southeast-1")}, "dysmsapi.ap-southeast-1.aliyuncs.com"},
+		{[]message.Param{message.Endpoint("sms.example.com")}, "sms.example.com"},
+		{[]message.Param{message.RegionID("ap-southeast-1"), message.Endpoint("sms.example.com")}, "sms.example.com"},
+	}
+
+	for _, tt := range tests {
+		host := ""
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			host = req.URL.Host
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, tt.params...); err != nil {
+			t.Errorf("SendSMS() error: %v", err)
+			continue
+		}
+		if host != tt.host {
+			t.Errorf("host = %v, want %v", host, tt.host)
+		}
+	}
+}
+
+func TestClientRequestID(t *testing.T) {
+	recorded := []string{}
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		// Record the client request ID like a tracing hook.
+		recorded = append(recorded, message.ClientRequestIDFromContext(req.Context()))
+		for key := range req.URL.Query() {
+			if key == "ClientRequestId" || req.URL.Query().Get(key) == "trace-0001" {
+				t.Errorf("client request ID is sent to aliyun in %v", key)
+			}
+		}
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.ClientRequestID("trace-0001")); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+
+	want := []string{"trace-0001", ""}
+	if len(recorded) != 2 || recorded[0] != want[0] || recorded[1] != want[1] {
+		t.Errorf("recorded client request IDs = %q, want %q", recorded, want)
+	}
+}
+
+func TestTrimTemplateParam(t *testing.T) {
+	// send sends the SMS with fixed timestamp and nonce and returns the sent template param and signature.
+	send := func(templateParam string, params ...message.Param) (string, string) {
+		query := url.Values{}
+		client := message.NewClient("testId", "testSecret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		timestamp, _ := time.Parse(time.RFC3339, "2017-07-12T02:42:19Z")
+		params = append(params, message.Timestamp(timestamp), message.SignatureNonce("nonce"))
+		if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", templateParam, params...); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+		return query.Get("TemplateParam"), query.Get("Signature")
+	}
+
+	_, trimmedSignature := send(`{"code":"1234"}`)
+
+	param, signature := send("{\"code\":\"1234\"}\n")
+	if param != "{\"code\":\"1234\"}\n" {
+		t.Errorf("template param without trimming = %q, want the newline kept", param)
+	}
+	if signature == trimmedSignature {
+		t.Errorf("signature without trimming = %v, want it differs from the trimmed one", signature)
+	}
+
+	param, signature = send("{\"code\":\"1234\"}\r\n", message.TrimTemplateParam())
+	if param != `{"code":"1234"}` {
+		t.Errorf("template param with trimming = %q, want %q", param, `{"code":"1234"}`)
+	}
+	if signature != trimmedSignature {
+		t.Errorf("signature with trimming = %v, want %v", signature, trimmedSignature)
+	}
+}
+
+func TestValidateTemplateParam(t *testing.T) {
+	tests := []struct {
+		templateParam string
+		params        []message.Param
+		sent          bool
+	}{
+		{`{"code":"1234"}`, []message.Param{message.ValidateTemplateParam()}, true},
+		{`{"code":"1234"`, []message.Param{message.ValidateTemplateParam()}, false},
+		{`{"code":"1234"}` + "\n", []message.Param{message.TrimTemplateParam(), message.ValidateTemplateParam()}, true},
+		// Empty param of the template without variables.
+		{"", []message.Param{message.ValidateTemplateParam()}, true},
+		// Not validated by default.
+		{`{"code":"1234"`, nil, true},
+	}
+
+	for _, tt := range tests {
+		sent := false
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = true
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		_, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", tt.templateParam, tt.params...)
+		if sent != tt.sent || (err == nil) != tt.sent {
+			t.Errorf("SendSMS(%q) sent: %v, error: %v, want sent: %v", tt.templateParam, sent, err, tt.sent)
+		}
+	}
+}
+
+func TestTemplateParam(t *testing.T) {
+	got, err := message.TemplateParam(map[string]string{"code": "1234", "product": `"ytx"\`})
+	if err != nil {
+		t.Fatalf("TemplateParam() error: %v", err)
+	}
+
+	want := `{"code":"1234","product":"\"ytx\"\\"}`
+	if got != want {
+		t.Errorf("TemplateParam() = %v, want %v", got, want)
+	}
+}
+
+func TestGenPhoneNumbersStr(t *testing.T) {
+	tests := []struct {
+		nums []string
+		want string
+	}{
+		{[]string{"13800138000"}, "13800138000"},
+		{[]string{"13800138000", "13900139000", "13700137000"}, "13800138000,13900139000,13700137000"},
+		{[]string{}, ""},
+		{nil, ""},
+		{[]string{"13800138000", ""}, "13800138000"},
+		{[]string{"", "13800138000", " ", "13900139000", ""}, "13800138000,13900139000"},
+		{[]string{"", ""}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := message.GenPhoneNumbersStr(tt.nums); got != tt.want {
+			t.Errorf("GenPhoneNumbersStr(%q) = %v, want %v", tt.nums, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizePhoneNumbers(t *testing.T) {
+	tests := []struct {
+		nums []string
+		want []string
+		err  bool
+	}{
+		{[]string{"13800138000"}, []string{"13800138000"}, false},
+		{[]string{" 138 0013 8000 ", "+86 13900139000", "0086-13700137000"}, []string{"13800138000", "13900139000", "13700137000"}, false},
+		{[]string{"+852 0000 0000", "0085200000000"}, []string{"85200000000", "85200000000"}, false},
+		{[]string{}, []string{}, false},
+		{[]string{"13800138000", ""}, nil, true},
+		{[]string{"1380013800a"}, nil, true},
+		{[]string{"23800138000"}, nil, true},
+		{[]string{"+86 1380013800"}, nil, true},
+		{[]string{"+1234567"}, nil, true},
+		{[]string{"+1234567890123456"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := message.NormalizePhoneNumbers(tt.nums)
+		if (err != nil) != tt.err {
+			t.Errorf("NormalizePhoneNumbers(%q) error = %v, want error: %v", tt.nums, err, tt.err)
+			continue
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("NormalizePhoneNumbers(%q) = %q, want %q", tt.nums, got, tt.want)
+		}
+	}
+}
+
+func TestGenNormalizedPhoneNumbersStr(t *testing.T) {
+	got, err := message.GenNormalizedPhoneNumbersStr([]string{"+86 138 0013 8000", "13900139000"})
+	if err != nil || got != "13800138000,13900139000" {
+		t.Errorf("GenNormalizedPhoneNumbersStr() = %v, %v, want 13800138000,13900139000, nil", got, err)
+	}
+
+	if _, err := message.GenNormalizedPhoneNumbersStr([]string{"13800138000", ""}); err == nil {
+		t.Errorf("GenNormalizedPhoneNumbersStr() error = nil, want error of the empty entry")
+	}
+}
+
+func TestVoiceParams(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+	params := []message.Param{message.PlayTimes(2), message.Volume(80), message.Speed(100)}
+	want := map[string]string{"PlayTimes": "2", "Volume": "80", "Speed": "100"}
+
+	if _, _, err := client.MakeSingleCallByTTS("02560000000", "1500000000", "TTS_0000", `{"code":"1234"}`, params...); err != nil {
+		t.Fatalf("MakeSingleCallByTTS() error: %v", err)
+	}
+	for key, value := range want {
+		if got := query.Get(key); got != value {
+			t.Errorf("MakeSingleCallByTTS() %v = %v, want %v", key, got, value)
+		}
+	}
+
+	// Ignored for SMS.
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, params...); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+	for key := range want {
+		if _, ok := query[key]; ok {
+			t.Errorf("SendSMS() sends %v, want it ignored", key)
+		}
+	}
+}
+
+func TestSecurityToken(t *testing.T) {
+	var req *http.Request
+	client := message.NewClient("STS.testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.SecurityToken("my token")); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+
+	params, _ := sentParams(req)
+	want, canonical := message.Sign(params, "testSecret")
+	if !strings.Contains(canonical, "&SecurityToken=my%20token&") {
+		t.Errorf("canonical string = %v, want it contains SecurityToken", canonical)
+	}
+
+	assertSigned(t, req, "testSecret")
+
+	// The signature covers the token.
+	delete(params, "SecurityToken")
+	if unsigned, _ := message.Sign(params, "testSecret"); unsigned == want {
+		t.Errorf("signature without SecurityToken = %v, want it differs", unsigned)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Respond slowly.
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = newLocalTransport(srv)
+
+	tests := []struct {
+		ctxTimeout time.Duration
+		timeout    time.Duration
+	}{
+		{0, 50 * time.Millisecond},
+		// The earlier deadline wins.
+		{50 * time.Millisecond, time.Hour},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.ctxTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+			defer cancel()
+		}
+
+		start := time.Now()
+		_, _, err := client.SendSMSContext(ctx, []string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.Timeout(tt.timeout))
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("SendSMSContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("SendSMSContext() returns after %v, want it times out promptly", elapsed)
+		}
+	}
+
+	// The timeout of the shared http.Client is not changed.
+	if client.Timeout != 0 {
+		t.Errorf("client.Timeout = %v, want 0", client.Timeout)
+	}
+}
+
+func TestScheme(t *testing.T) {
+	tests := []struct {
+		params []message.Param
+		scheme string
+	}{
+		{nil, "https"},
+		{[]message.Param{message.Scheme("https")}, "https"},
+		{[]message.Param{message.Scheme("http")}, "http"},
+	}
+
+	signatures := map[string]bool{}
+	for _, tt := range tests {
+		var u *url.URL
+		client := message.NewClient("testId", "testSecret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			u = req.URL
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		timestamp, _ := time.Parse(time.RFC3339, "2017-07-12T02:42:19Z")
+		params := append(tt.params, message.Timestamp(timestamp), message.SignatureNonce("nonce"))
+		if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, params...); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+		if u.Scheme != tt.scheme {
+			t.Errorf("scheme = %v, want %v", u.Scheme, tt.scheme)
+		}
+		signatures[u.Query().Get("Signature")] = true
+	}
+
+	if len(signatures) != 1 {
+		t.Errorf("signatures = %v, want the same signature for all schemes", signatures)
+	}
+}
+
+func TestEndpointForRegion(t *testing.T) {
+	tests := []struct {
+		regionID string
+		want     string
+	}{
+		{"", "dysmsapi.aliyuncs.com"},
+		{"cn-hangzhou", "dysmsapi.aliyuncs.com"},
+		{"ap-southeast-1", "dysmsapi.ap-southeast-1.aliyuncs.com"},
+	}
+
+	for _, tt := range tests {
+		if got := message.EndpointForRegion(tt.regionID); got != tt.want {
+			t.Errorf("EndpointForRegion(%q) = %v, want %v", tt.regionID, got, tt.want)
+		}
+
+		// Same host as the one used by the client.
+		if host, err := message.EndpointForService(tt.regionID, message.ServiceSMS); err != nil || host != tt.want {
+			t.Errorf("EndpointForService(%q, %q) = %v, %v, want %v", tt.regionID, message.ServiceSMS, host, err, tt.want)
+		}
+	}
+}
+
+func TestEndpointForRegionUnknown(t *testing.T) {
+	tests := []struct {
+		regionID string
+		want     string
+	}{
+		// The host of the well-formed region ID is derived.
+		{"ap-northeast-1", "dysmsapi.ap-northeast-1.aliyuncs.com"},
+		// Malformed region IDs are never formatted into a host.
+		{"mars-1.example.com/", "dysmsapi.aliyuncs.com"},
+		{"mars 1", "dysmsapi.aliyuncs.com"},
+	}
+
+	for _, tt := range tests {
+		if got := message.EndpointForRegion(tt.regionID); got != tt.want {
+			t.Errorf("EndpointForRegion(%q) = %v, want %v", tt.regionID, got, tt.want)
+		}
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	expired := `{"RequestId":"STUB-EXPIRED","Code":"InvalidTimeStamp.Expired","Message":"Specified time stamp or date value is expired."}`
+	errNetwork := errors.New("connection reset by peer")
+
+	tests := []struct {
+		idempotent bool
+		// errs are errors of the transport for each attempt. Responses are returned for nil errors.
+		errs   []error
+		bodies []string
+		ok     bool
+		// nonces are the expected number of different nonces.
+		nonces int
+	}{
+		// Network errors are retried with the same nonce.
+		{true, []error{errNetwork, errNetwork, nil}, []string{"", "", `{"Code":"OK"}`}, true, 1},
+		// Expired timestamps are retried with a regenerated nonce.
+		{true, []error{nil, nil}, []string{expired, `{"Code":"OK"}`}, true, 2},
+		// Network errors are not retried for requests which are not idempotent.
+		{false, []error{errNetwork, nil}, []string{"", `{"Code":"OK"}`}, false, 1},
+	}
+
+	for _, tt := range tests {
+		requests := []*http.Request{}
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(3, time.Millisecond))
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			i := len(requests)
+			requests = append(requests, req)
+			if tt.errs[i] != nil {
+				return nil, tt.errs[i]
+			}
+			return newStubResponse(http.StatusOK, tt.bodies[i]), nil
+		})
+
+		params := []message.Param{}
+		if tt.idempotent {
+			params = append(params, message.Idempotent("my_nonce", "my_out_id"))
+		}
+		ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, params...)
+		if ok != tt.ok || (err == nil) != tt.ok {
+			t.Errorf("SendSMS() = %v, %v, want ok: %v", ok, err, tt.ok)
+		}
+
+		nonces := map[string]bool{}
+		for _, req := range requests {
+			params, _ := sentParams(req)
+			nonces[params["SignatureNonce"]] = true
+			if tt.idempotent && params["OutId"] != "my_out_id" {
+				t.Errorf("OutId = %v, want my_out_id", params["OutId"])
+			}
+
+			// Requests are re-signed for each attempt.
+			assertSigned(t, req, "my_key_secret")
+		}
+		if len(nonces) != tt.nonces {
+			t.Errorf("nonces = %v, want %v different nonces", nonces, tt.nonces)
+		}
+		if tt.idempotent && tt.nonces == 1 && !nonces["my_nonce"] {
+			t.Errorf("nonces = %v, want my_nonce", nonces)
+		}
+	}
+}
+
+func TestIdempotentResponseLost(t *testing.T) {
+	requests := 0
+	client := message.NewClient("my_key_id", "my_key_secret", message.WithRetry(3, time.Millisecond))
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		if requests == 1 {
+			// The SMS is sent but the response is lost.
+			return nil, errors.New("connection reset by peer")
+		}
+		// The retry with the same nonce is rejected.
+		return newStubResponse(http.StatusBadRequest, `{"RequestId":"STUB-NONCE","Code":"SignatureNonceUsed","Message":"Specified signature nonce was used already."}`), nil
+	})
+
+	ok, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.Idempotent("my_nonce", "my_out_id"))
+	var apiErr *message.APIError
+	if ok || !errors.As(err, &apiErr) || apiErr.Code != message.CodeSignatureNonceUsed {
+		t.Errorf("SendSMS() = %v, %v, want false, *message.APIError of %v", ok, err, message.CodeSignatureNonceUsed)
+	}
+	// Not retried again.
+	if requests != 2 {
+		t.Errorf("requests = %v, want 2", requests)
+	}
+}
+
+func TestMethodPost(t *testing.T) {
+	var req *http.Request
+	var body []byte
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		body, _ = io.ReadAll(r.Body)
+		return newStubResponse(http.StatusOK, `{"Code":"OK","BizId":"134523^4351232"}`), nil
+	})
+
+	// Large batch payload which overflows URL length limits.
+	n := 1000
+	phoneNumbers := make([]string, n)
+	signNames := make([]string, n)
+	templateParams := make([]string, n)
+	for i := 0; i < n; i++ {
+		phoneNumbers[i] = fmt.Sprintf("138%08d", i)
+		signNames[i] = "阿里云短信测试专用"
+		templateParams[i] = fmt.Sprintf(`{"customer":"test %d"}`, i)
+	}
+
+	ok, _, err := client.SendBatchSMS(phoneNumbers, signNames, "SMS_0000", templateParams, message.Method("post"))
+	if !ok || err != nil {
+		t.Fatalf("SendBatchSMS() = %v, %v, want true, nil", ok, err)
+	}
+
+	if req.Method != http.MethodPost || req.URL.RawQuery != "" {
+		t.Errorf("request = %v %v, want POST without query", req.Method, req.URL)
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %v, want application/x-www-form-urlencoded", contentType)
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatalf("ParseQuery() error: %v", err)
+	}
+	if got := form.Get("TemplateParamJson"); !strings.Contains(got, `{"customer":"test 999"}`) {
+		t.Errorf("TemplateParamJson = %v, want all template params", got)
+	}
+
+	// The canonicalized query string is signed with "POST".
+	signature := form.Get("Signature")
+	form.Del("Signature")
+	stringToSign := message.StringToSign("POST", message.CanonicalizedQuery(form))
+	if want, _ := url.QueryUnescape(popSignature("my_key_secret", stringToSign)); signature != want {
+		t.Errorf("signature = %v, want %v", signature, want)
+	}
+}
+
+func TestMethodInvalid(t *testing.T) {
+	requests := 0
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.Method("PUT")); err == nil {
+		t.Errorf("SendSMS() with method PUT returns no error")
+	}
+	if requests != 0 {
+		t.Errorf("requests = %v, want 0", requests)
+	}
+}
+
+func TestGenSendDate(t *testing.T) {
+	tests := []struct {
+		t    string
+		want string
+	}{
+		{"2019-01-08T08:00:00Z", "20190108"},
+		// It's the next day in China Standard Time.
+		{"2018-12-31T16:00:00Z", "20190101"},
+		{"2018-12-31T15:59:59Z", "20181231"},
+		{"2019-03-05T01:00:00+08:00", "20190305"},
+	}
+
+	for _, tt := range tests {
+		ts, _ := time.Parse(time.RFC3339, tt.t)
+		if got := message.GenSendDate(ts); got != tt.want {
+			t.Errorf("GenSendDate(%v) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestGenSendDateRange(t *testing.T) {
+	tests := []struct {
+		start string
+		end   string
+		want  []string
+	}{
+		// Year boundary.
+		{"2018-12-30T08:00:00+08:00", "2019-01-02T08:00:00+08:00", []string{"20181230", "20181231", "20190101", "20190102"}},
+		// Single day.
+		{"2019-01-08T01:00:00+08:00", "2019-01-08T23:00:00+08:00", []string{"20190108"}},
+		// Single day in China Standard Time across UTC days.
+		{"2019-01-07T17:00:00Z", "2019-01-08T15:00:00Z", []string{"20190108"}},
+		// End is before start.
+		{"2019-01-08T08:00:00+08:00", "2019-01-07T08:00:00+08:00", []string{}},
+	}
+
+	for _, tt := range tests {
+		start, _ := time.Parse(time.RFC3339, tt.start)
+		end, _ := time.Parse(time.RFC3339, tt.end)
+		got := message.GenSendDateRange(start, end)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || len(got) != len(tt.want) {
+			t.Errorf("GenSendDateRange(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestChunkPhoneNumbers(t *testing.T) {
+	nums := []string{"13800138000", "13800138001", "13800138002", "13800138003", "13800138004"}
+
+	tests := []struct {
+		nums []string
+		size int
+		want [][]string
+	}{
+		{nums, 2, [][]string{{"13800138000", "13800138001"}, {"13800138002", "13800138003"}, {"13800138004"}}},
+		{nums, 5, [][]string{nums}},
+		{nums, 0, [][]string{nums}},
+		{nil, 2, [][]string{}},
+	}
+
+	for _, tt := range tests {
+		got := message.ChunkPhoneNumbers(tt.nums, tt.size)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) || len(got) != len(tt.want) {
+			t.Errorf("ChunkPhoneNumbers(%v, %v) = %v, want %v", tt.nums, tt.size, got, tt.want)
+		}
+	}
+
+	// Appending to a chunk does not overwrite the next one.
+	chunks := message.ChunkPhoneNumbers(nums, 2)
+	_ = append(chunks[0], "13900139000")
+	if chunks[1][0] != "13800138002" {
+		t.Errorf("chunks[1][0] = %v, want 13800138002", chunks[1][0])
+	}
+}
+
+func TestSignatureNonceDeterministic(t *testing.T) {
+	generated := 0
+	urls := []string{}
+	client := message.NewClient("testId", "testSecret",
+		message.WithNonceSource(func() string {
+			generated++
+			return fmt.Sprintf("generated-%d", generated)
+		}),
+		message.WithRetry(2, time.Millisecond),
+	)
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		urls = append(urls, req.URL.String())
+		// Retry the first request.
+		if len(urls) == 1 {
+			return newStubResponse(http.StatusServiceUnavailable, "Service Unavailable"), nil
+		}
+		return newStubResponse(http.StatusOK, `<SendSmsResponse><Code>OK</Code></SendSmsResponse>`), nil
+	})
+
+	timestamp, _ := time.Parse(time.RFC3339, "2017-07-12T02:42:19Z")
+	for i := 0; i < 2; i++ {
+		// The nonce is specified before other params but still overrides the generated one.
+		if _, _, err := client.SendSMS(
+			[]string{"15300000001"},
+			"阿里云短信测试专用",
+			"SMS_71390007",
+			`{"customer":"test"}`,
+			message.SignatureNonce("45e25e9b-0a6f-4070-8c85-2956eda1b466"),
+			message.Timestamp(timestamp),
+			message.Format("XML"),
+			message.OutID("123"),
+		); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+	}
+
+	// The retry and the second call send the same signed URL.
+	want := "https://dysmsapi.aliyuncs.com/?Signature=zJDF%2BLrzhj%2FThnlvIToysFRq6t4%3D&AccessKeyId=testId&Action=SendSms&Format=XML&OutId=123&PhoneNumbers=15300000001&RegionId=cn-hangzhou&SignName=%E9%98%BF%E9%87%8C%E4%BA%91%E7%9F%AD%E4%BF%A1%E6%B5%8B%E8%AF%95%E4%B8%93%E7%94%A8&SignatureMethod=HMAC-SHA1&SignatureNonce=45e25e9b-0a6f-4070-8c85-2956eda1b466&SignatureVersion=1.0&TemplateCode=SMS_71390007&TemplateParam=%7B%22customer%22%3A%22test%22%7D&Timestamp=2017-07-12T02%3A42%3A19Z&Version=2017-05-25"
+	if len(urls) != 3 {
+		t.Fatalf("requests = %v, want 3", len(urls))
+	}
+	for i, u := range urls {
+		if u != want {
+			t.Errorf("URL of request %d = %v, want %v", i, u, want)
+		}
+	}
+
+	// The same signed string for the same parameters.
+	v := url.Values{}
+	for key, value := range docParams {
+		v.Set(key, value)
+	}
+	query := message.CanonicalizedQuery(v)
+	if got := client.SignedString("GET", query); got != "zJDF%2BLrzhj%2FThnlvIToysFRq6t4%3D" {
+		t.Errorf("SignedString() = %v, want zJDF%%2BLrzhj%%2FThnlvIToysFRq6t4%%3D", got)
+	}
+}
+
+func TestParamsOverrideArguments(t *testing.T) {
+	tests := []struct {
+		phoneNumbers     []string
+		signName         string
+		params           []message.Param
+		wantPhoneNumbers string
+		wantSignName     string
+	}{
+		// Arguments are sent without params.
+		{[]string{"13800138000"}, "my_product", nil, "13800138000", "my_product"},
+		// Params override arguments.
+		{[]string{"13800138000"}, "my_product", []message.Param{message.PhoneNumbers([]string{"13900139000", "13900139001"}), message.SignName("other_product")}, "13900139000,13900139001", "other_product"},
+		// Params override defaults of the client.
+		{[]string{"13800138000"}, "", []message.Param{message.SignName("other_product")}, "13800138000", "other_product"},
+		// Phone numbers are specified by params only.
+		{nil, "my_product", []message.Param{message.PhoneNumbers([]string{"13900139000"})}, "13900139000", "my_product"},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret", message.WithDefaultSignName("default_product"))
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		if _, _, err := client.SendSMS(tt.phoneNumbers, tt.signName, "SMS_0000", `{"code":"1234"}`, tt.params...); err != nil {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+		if query.Get("PhoneNumbers") != tt.wantPhoneNumbers || query.Get("SignName") != tt.wantSignName {
+			t.Errorf("PhoneNumbers = %v, SignName = %v, want %v, %v", query.Get("PhoneNumbers"), query.Get("SignName"), tt.wantPhoneNumbers, tt.wantSignName)
+		}
+	}
+
+	// The phone numbers are checked after they're overridden by params.
+	client := message.NewClient("my_key_id", "my_key_secret")
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`, message.PhoneNumbers(nil)); !errors.Is(err, message.ErrNoPhoneNumbers) {
+		t.Errorf("SendSMS() with empty PhoneNumbers() error = %v, want %v", err, message.ErrNoPhoneNumbers)
+	}
+}
+
+func TestOutIDAndSmsUpExtendCode(t *testing.T) {
+	var req *http.Request
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`,
+		message.OutID("msg-uuid-1"),
+		message.SmsUpExtendCode("90999"),
+	); err != nil {
+		t.Fatalf("SendSMS() error: %v", err)
+	}
+
+	// Both are in the canonicalized query string which is signed.
+	params, _ := sentParams(req)
+	v := url.Values{}
+	for key, value := range params {
+		v.Set(key, value)
+	}
+	query := message.CanonicalizedQuery(v)
+	for _, want := range []string{"&OutId=msg-uuid-1&", "&SmsUpExtendCode=90999&"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("canonicalized query = %v, want it contains %v", query, want)
+		}
+	}
+
+	assertSigned(t, req, "my_key_secret")
+}
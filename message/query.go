@@ -0,0 +1,229 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SendDetail is the detail of a sent SMS.
+type SendDetail struct {
+	// PhoneNum is the phone number. e.g. "13800138000".
+	PhoneNum string `json:"PhoneNum" xml:"PhoneNum"`
+	// SendStatus is the status: 1: waiting for report, 2: failed, 3: delivered.
+	SendStatus int `json:"SendStatus" xml:"SendStatus"`
+	// ErrCode is the error code of the carrier. e.g. "DELIVERED".
+	ErrCode string `json:"ErrCode" xml:"ErrCode"`
+	// TemplateCode is the template code. e.g. "SMS_0000".
+	TemplateCode string `json:"TemplateCode" xml:"TemplateCode"`
+	// Content is the rendered content of the SMS.
+	Content string `json:"Content" xml:"Content"`
+	// SendDate is the send time. e.g. "2019-01-08 16:44:10".
+	SendDate string `json:"SendDate" xml:"SendDate"`
+	// ReceiveDate is the receive time. e.g. "2019-01-08 16:44:13".
+	ReceiveDate string `json:"ReceiveDate" xml:"ReceiveDate"`
+	// OutID is the caller's out ID. e.g. "123".
+	OutID string `json:"OutId" xml:"OutId"`
+}
+
+const (
+	// SendStatusWaiting is the status of the SMS waiting for the report.
+	SendStatusWaiting = 1
+	// SendStatusFailed is the status of the SMS failed to deliver.
+	SendStatusFailed = 2
+	// SendStatusDelivered is the status of the delivered SMS.
+	SendStatusDelivered = 3
+)
+
+// QuerySendDetailsResponse is the response of HTTP request of querying send details.
+type QuerySendDetailsResponse struct {
+	Response
+	// TotalCount is the total count of send details of all pages.
+	TotalCount int `xml:"TotalCount"`
+	// Details are send details of current page.
+	Details []SendDetail `xml:"SmsSendDetailDTOs>SmsSendDetailDTO"`
+}
+
+// UnmarshalJSON parses the JSON response.
+// aliyun encodes "TotalCount" as a JSON number or string, both are accepted.
+// Send details are nested under "SmsSendDetailDTOs" > "SmsSendDetailDTO".
+func (r *QuerySendDetailsResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Response
+		TotalCount        json.Number `json:"TotalCount"`
+		SmsSendDetailDTOs struct {
+			SmsSendDetailDTO []SendDetail `json:"SmsSendDetailDTO"`
+		} `json:"SmsSendDetailDTOs"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	r.Response = raw.Response
+	r.Details = raw.SmsSendDetailDTOs.SmsSendDetailDTO
+	r.TotalCount = 0
+	if raw.TotalCount != "" {
+		n, err := raw.TotalCount.Int64()
+		if err != nil {
+			return err
+		}
+		r.TotalCount = int(n)
+	}
+	return nil
+}
+
+// QuerySendDetails queries the send details of SMS sent to the phone number.
+//
+// phoneNumber: the phone number which SMS sent to.
+// bizID: optional business ID returned by SendSMS(). Pass "" to query all SMS of the day.
+// sendDate: the date of sending SMS in "yyyyMMdd" format. e.g. "20180101". Only the last 30 days are supported.
+// Use GenSendDate() to generate it.
+// pageSize: page size of the results. Range: 1 - 50.
+// currentPage: page number of the results. It begins from 1.
+// params: optional parameters for querying send details.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) QuerySendDetails(phoneNumber, bizID, sendDate string, pageSize, currentPage int, params ...Param) (bool, *QuerySendDetailsResponse, error) {
+	return c.QuerySendDetailsContext(context.Background(), phoneNumber, bizID, sendDate, pageSize, currentPage, params...)
+}
+
+// QuerySendDetailsContext queries the send details of SMS sent to the phone number with the context.
+// See QuerySendDetails() for other parameters.
+func (c *Client) QuerySendDetailsContext(ctx context.Context, phoneNumber, bizID, sendDate string, pageSize, currentPage int, params ...Param) (bool, *QuerySendDetailsResponse, error) {
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for querying send details.
+	v.Set("Action", "QuerySendDetails")
+	v.Set("Version", smsVersion)
+	v.Set("RegionId", DefaultRegionID)
+
+	// Set required business parameters
+	v.Set("PhoneNumber", phoneNumber)
+	if bizID != "" {
+		v.Set("BizId", bizID)
+	}
+	v.Set("SendDate", sendDate)
+	v.Set("PageSize", strconv.Itoa(pageSize))
+	v.Set("CurrentPage", strconv.Itoa(currentPage))
+
+	// Override parameters if need.
+	o, err := applyParams(v, params)
+	if err != nil {
+		return false, nil, err
+	}
+	ignoreVoiceParams(v)
+
+	response := &QuerySendDetailsResponse{}
+	host, err := serviceHost(v, o, ServiceSMS)
+	if err != nil {
+		return false, nil, err
+	}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+const (
+	// queryAllPageSize is the page size of QueryAllSendDetails(). It's the max page size.
+	queryAllPageSize = 50
+	// queryAllPageDelay is the delay between pages of QueryAllSendDetails() to avoid throttling.
+	queryAllPageDelay = 50 * time.Millisecond
+)
+
+// QueryAllSendDetails queries the send details of all pages of SMS sent to the phone number.
+// Pages are queried in order until TotalCount is exhausted with a small delay between pages to avoid throttling.
+// It stops when the context is canceled.
+//
+// See QuerySendDetails() for parameters.
+//
+// It returns the send details of all pages in order and error.
+// The error is an *APIError if the status code of any response is not "OK".
+func (c *Client) QueryAllSendDetails(ctx context.Context, phoneNumber, bizID, sendDate string, params ...Param) ([]SendDetail, error) {
+	details := []SendDetail{}
+	for page := 1; ; page++ {
+		if page > 1 {
+			if err := sleepContext(ctx, queryAllPageDelay); err != nil {
+				return nil, err
+			}
+		}
+
+		ok, resp, err := c.QuerySendDetailsContext(ctx, phoneNumber, bizID, sendDate, queryAllPageSize, page, params...)
+		if !ok {
+			return nil, err
+		}
+
+		details = append(details, resp.Details...)
+		if len(resp.Details) == 0 || len(details) >= resp.TotalCount {
+			return details, nil
+		}
+	}
+}
+
+// DeliveryUpdate is the update of the send status emitted by WatchDeliveries().
+type DeliveryUpdate struct {
+	// Detail is the send detail of the SMS. It's empty if Err is not nil.
+	Detail SendDetail
+	// Err is the error of querying the send details. Watching goes on after an error.
+	// It's an *APIError if the status code of the response is not "OK".
+	Err error
+}
+
+// WatchDeliveries polls QuerySendDetails() for the SMS sent to the number and emits the send status changes.
+// It's for apps which don't receive delivery reports by push callbacks.
+//
+// bizID: business ID returned by SendSMS().
+// number: the phone number which SMS sent to.
+// interval: the interval between queries.
+// params: optional parameters for querying send details.
+// The send date is the day when watching begins.
+//
+// It returns a channel of updates. An update is emitted when the send status changes or a query fails.
+// The channel is closed after the SMS is delivered or failed, or the context is done.
+func (c *Client) WatchDeliveries(ctx context.Context, bizID, number string, interval time.Duration, params ...Param) <-chan DeliveryUpdate {
+	ch := make(chan DeliveryUpdate)
+	sendDate := GenSendDate(c.now())
+
+	go func() {
+		defer close(ch)
+
+		status := 0
+		for {
+			var update *DeliveryUpdate
+			ok, resp, err := c.QuerySendDetailsContext(ctx, number, bizID, sendDate, queryAllPageSize, 1, params...)
+			switch {
+			case !ok:
+				if ctx.Err() != nil {
+					return
+				}
+				update = &DeliveryUpdate{Err: err}
+			case len(resp.Details) > 0 && resp.Details[0].SendStatus != status:
+				status = resp.Details[0].SendStatus
+				update = &DeliveryUpdate{Detail: resp.Details[0]}
+			}
+
+			if update != nil {
+				select {
+				case ch <- *update:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if status == SendStatusDelivered || status == SendStatusFailed {
+				return
+			}
+			if err := sleepContext(ctx, interval); err != nil {
+				return
+			}
+		}
+	}()
+
+	return ch
+}
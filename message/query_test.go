@@ -0,0 +1,190 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/testhelper"
+)
+
+func TestQuerySendDetails(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"TotalCount":2,"Message":"OK","RequestId":"819BE656-D2E0-4858-8B21-B2E477085AAF","SmsSendDetailDTOs":{"SmsSendDetailDTO":[{"SendDate":"2019-01-08 16:44:10","SendStatus":3,"ReceiveDate":"2019-01-08 16:44:13","ErrCode":"DELIVERED","TemplateCode":"SMS_0000","Content":"验证码为：1234","PhoneNum":"13800138000","OutId":"123"},{"SendDate":"2019-01-08 16:45:10","SendStatus":1,"ErrCode":"","TemplateCode":"SMS_0000","Content":"验证码为：5678","PhoneNum":"13800138000"}]},"Code":"OK"}`},
+		{"JSON", `{"TotalCount":"2","Message":"OK","RequestId":"819BE656-D2E0-4858-8B21-B2E477085AAF","SmsSendDetailDTOs":{"SmsSendDetailDTO":[{"SendDate":"2019-01-08 16:44:10","SendStatus":3,"ReceiveDate":"2019-01-08 16:44:13","ErrCode":"DELIVERED","TemplateCode":"SMS_0000","Content":"验证码为：1234","PhoneNum":"13800138000","OutId":"123"},{"SendDate":"2019-01-08 16:45:10","SendStatus":1,"ErrCode":"","TemplateCode":"SMS_0000","Content":"验证码为：5678","PhoneNum":"13800138000"}]},"Code":"OK"}`},
+		{"XML", `<QuerySendDetailsResponse><TotalCount>2</TotalCount><Message>OK</Message><RequestId>819BE656-D2E0-4858-8B21-B2E477085AAF</RequestId><SmsSendDetailDTOs><SmsSendDetailDTO><SendDate>2019-01-08 16:44:10</SendDate><SendStatus>3</SendStatus><ReceiveDate>2019-01-08 16:44:13</ReceiveDate><ErrCode>DELIVERED</ErrCode><TemplateCode>SMS_0000</TemplateCode><Content>验证码为：1234</Content><PhoneNum>13800138000</PhoneNum><OutId>123</OutId></SmsSendDetailDTO><SmsSendDetailDTO><SendDate>2019-01-08 16:45:10</SendDate><SendStatus>1</SendStatus><TemplateCode>SMS_0000</TemplateCode><Content>验证码为：5678</Content><PhoneNum>13800138000</PhoneNum></SmsSendDetailDTO></SmsSendDetailDTOs><Code>OK</Code></QuerySendDetailsResponse>`},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.QuerySendDetails("13800138000", "134523^4351232", "20190108", 10, 1, message.Format(tt.format))
+		if err != nil || !ok {
+			t.Fatalf("QuerySendDetails() = %v, %v, %v, want OK response", ok, resp, err)
+		}
+
+		for key, want := range map[string]string{
+			"Action":      "QuerySendDetails",
+			"PhoneNumber": "13800138000",
+			"BizId":       "134523^4351232",
+			"SendDate":    "20190108",
+			"PageSize":    "10",
+			"CurrentPage": "1",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("%v = %v, want %v", key, got, want)
+			}
+		}
+
+		if resp.TotalCount != 2 || len(resp.Details) != 2 {
+			t.Fatalf("TotalCount = %v, details = %v, want 2 details", resp.TotalCount, resp.Details)
+		}
+		want := message.SendDetail{
+			PhoneNum:     "13800138000",
+			SendStatus:   message.SendStatusDelivered,
+			ErrCode:      "DELIVERED",
+			TemplateCode: "SMS_0000",
+			Content:      "验证码为：1234",
+			SendDate:     "2019-01-08 16:44:10",
+			ReceiveDate:  "2019-01-08 16:44:13",
+			OutID:        "123",
+		}
+		if resp.Details[0] != want {
+			t.Errorf("details[0] = %+v, want %+v", resp.Details[0], want)
+		}
+		if resp.Details[1].SendStatus != message.SendStatusWaiting {
+			t.Errorf("details[1].SendStatus = %v, want %v", resp.Details[1].SendStatus, message.SendStatusWaiting)
+		}
+	}
+}
+
+// sendDetailsPage returns the JSON response of the page of send details.
+func sendDetailsPage(totalCount, page, pageSize int) string {
+	details := []string{}
+	for i := (page - 1) * pageSize; i < page*pageSize && i < totalCount; i++ {
+		details = append(details, fmt.Sprintf(`{"PhoneNum":"13800138000","SendStatus":3,"OutId":"%d"}`, i))
+	}
+	return fmt.Sprintf(`{"Code":"OK","TotalCount":%d,"SmsSendDetailDTOs":{"SmsSendDetailDTO":[%s]}}`, totalCount, strings.Join(details, ","))
+}
+
+func TestQueryAllSendDetails(t *testing.T) {
+	pages := []string{}
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		pages = append(pages, query.Get("CurrentPage"))
+		page, _ := strconv.Atoi(query.Get("CurrentPage"))
+		pageSize, _ := strconv.Atoi(query.Get("PageSize"))
+		return newStubResponse(http.StatusOK, sendDetailsPage(120, page, pageSize)), nil
+	})
+
+	details, err := client.QueryAllSendDetails(context.Background(), "13800138000", "", "20190108")
+	if err != nil {
+		t.Fatalf("QueryAllSendDetails() error: %v", err)
+	}
+	if want := []string{"1", "2", "3"}; strings.Join(pages, ",") != strings.Join(want, ",") {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+
+	// Details of all pages are in order.
+	if len(details) != 120 {
+		t.Fatalf("len(details) = %v, want 120", len(details))
+	}
+	for i, detail := range details {
+		if detail.OutID != strconv.Itoa(i) {
+			t.Errorf("details[%d].OutID = %v, want %v", i, detail.OutID, i)
+		}
+	}
+}
+
+func TestQueryAllSendDetailsCanceled(t *testing.T) {
+	requests := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		// Cancel after the first page.
+		cancel()
+		return newStubResponse(http.StatusOK, sendDetailsPage(120, requests, 50)), nil
+	})
+
+	details, err := client.QueryAllSendDetails(ctx, "13800138000", "", "20190108")
+	if !errors.Is(err, context.Canceled) || details != nil {
+		t.Errorf("QueryAllSendDetails() = %v, %v, want nil, %v", len(details), err, context.Canceled)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %v, want 1", requests)
+	}
+}
+
+func sendDetailsResponse(status int) testhelper.Response {
+	return testhelper.Response{
+		StatusCode: http.StatusOK,
+		Body:       fmt.Sprintf(`{"TotalCount":1,"Message":"OK","RequestId":"STUB-%d","SmsSendDetailDTOs":{"SmsSendDetailDTO":[{"SendDate":"2019-01-08 16:44:10","SendStatus":%d,"TemplateCode":"SMS_0000","PhoneNum":"13800138000"}]},"Code":"OK"}`, status, status),
+	}
+}
+
+func TestWatchDeliveries(t *testing.T) {
+	waiting := sendDetailsResponse(message.SendStatusWaiting)
+	delivered := sendDetailsResponse(message.SendStatusDelivered)
+	transport := testhelper.NewSequenceTransport(waiting, waiting, waiting, delivered)
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = transport
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var statuses []int
+	for update := range client.WatchDeliveries(ctx, "134523^4351232", "13800138000", time.Millisecond) {
+		if update.Err != nil {
+			t.Fatalf("WatchDeliveries() error = %v", update.Err)
+		}
+		statuses = append(statuses, update.Detail.SendStatus)
+	}
+
+	// Only the changes of the send status are emitted.
+	want := []int{message.SendStatusWaiting, message.SendStatusDelivered}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("WatchDeliveries() statuses = %v, want %v", statuses, want)
+	}
+	requests := transport.Requests()
+	if len(requests) != 4 {
+		t.Fatalf("requests = %v, want 4", len(requests))
+	}
+	query := requests[0].URL.Query()
+	if query.Get("BizId") != "134523^4351232" || query.Get("PhoneNumber") != "13800138000" {
+		t.Errorf("query = %v, want the biz ID and phone number", query)
+	}
+}
+
+func TestWatchDeliveriesCanceled(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = testhelper.NewSequenceTransport(sendDetailsResponse(message.SendStatusWaiting))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := client.WatchDeliveries(ctx, "134523^4351232", "13800138000", time.Millisecond)
+	if update := <-ch; update.Detail.SendStatus != message.SendStatusWaiting {
+		t.Errorf("WatchDeliveries() update = %+v, want waiting", update)
+	}
+
+	cancel()
+	for update := range ch {
+		t.Errorf("WatchDeliveries() update = %+v after the context is canceled, want closed", update)
+	}
+}
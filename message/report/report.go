@@ -0,0 +1,102 @@
+// Package report parses messages pushed by aliyun SMS service to the MNS queues.
+//
+// Upstream SMS replied by users are pushed as SmsUp messages.
+// Delivery receipts of sent SMS are pushed as SmsReport messages.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// SmsUp is the upstream SMS replied by a user.
+type SmsUp struct {
+	// PhoneNumber is the phone number of the user. e.g. "13800138000".
+	PhoneNumber string `json:"phone_number"`
+	// SignName is the sign name of the SMS replied to.
+	SignName string `json:"sign_name"`
+	// SendTime is the time the user sent the SMS. e.g. "2017-01-01 11:12:13".
+	SendTime string `json:"send_time"`
+	// SequenceID is the sequence ID of the upstream SMS.
+	SequenceID int64 `json:"sequence_id"`
+	// Content is the content of the upstream SMS.
+	Content string `json:"content"`
+	// DestCode is the extend code of the SMS replied to.
+	// It's the one specified by message.SmsUpExtendCode() when sending.
+	// It can be used to route replies back to the originating campaign.
+	DestCode string `json:"dest_code"`
+}
+
+// ParseSmsUp parses the SmsUp messages.
+// data: JSON of one SmsUp message or an array of them.
+func ParseSmsUp(data []byte) ([]SmsUp, error) {
+	ups := []SmsUp{}
+	if err := parseList(data, &ups); err != nil {
+		return nil, err
+	}
+	return ups, nil
+}
+
+// RouteSmsUp matches the upstream SMS to the campaigns which sent the SMS replied to by the extend code.
+//
+// ups: upstream SMS parsed by ParseSmsUp().
+// campaigns: campaign names keyed by the extend codes specified by message.SmsUpExtendCode() when sending.
+// e.g. {"1234": "spring-sale"}.
+//
+// It returns the upstream SMS of each campaign keyed by the campaign name and the ones which match no campaign.
+// The order of the upstream SMS is kept.
+func RouteSmsUp(ups []SmsUp, campaigns map[string]string) (map[string][]SmsUp, []SmsUp) {
+	routed := map[string][]SmsUp{}
+	unmatched := []SmsUp{}
+	for _, up := range ups {
+		campaign, ok := campaigns[up.DestCode]
+		if !ok || up.DestCode == "" {
+			unmatched = append(unmatched, up)
+			continue
+		}
+		routed[campaign] = append(routed[campaign], up)
+	}
+	return routed, unmatched
+}
+
+// SmsReport is the delivery receipt of a sent SMS.
+type SmsReport struct {
+	// PhoneNumber is the phone number the SMS is sent to. e.g. "13800138000".
+	PhoneNumber string `json:"phone_number"`
+	// SendTime is the time the SMS is sent. e.g. "2017-01-01 11:12:13".
+	SendTime string `json:"send_time"`
+	// ReportTime is the time the delivery status is reported. e.g. "2017-01-01 11:12:15".
+	ReportTime string `json:"report_time"`
+	// Success is true if the SMS is delivered.
+	Success bool `json:"success"`
+	// ErrCode is the error code of the delivery. e.g. "DELIVERED".
+	ErrCode string `json:"err_code"`
+	// ErrMsg is the detail message for the error code. e.g. "用户接收成功".
+	ErrMsg string `json:"err_msg"`
+	// SmsSize is the number of SMS the content is split into.
+	SmsSize string `json:"sms_size"`
+	// BizID is the business ID returned by SendSMS().
+	BizID string `json:"biz_id"`
+	// OutID is the caller's out ID specified by message.OutID() when sending.
+	OutID string `json:"out_id"`
+}
+
+// ParseSmsReport parses the SmsReport messages.
+// data: JSON of one SmsReport message or an array of them.
+func ParseSmsReport(data []byte) ([]SmsReport, error) {
+	reports := []SmsReport{}
+	if err := parseList(data, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// parseList parses the JSON of one object or an array of objects into the slice pointed by v.
+func parseList(data []byte, v interface{}) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '[' {
+		// Wrap the single object as an array.
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	return json.Unmarshal(data, v)
+}
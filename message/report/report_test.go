@@ -0,0 +1,161 @@
+package report_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/report"
+)
+
+// roundTripFunc is a http.RoundTripper stub.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestParseSmsUp(t *testing.T) {
+	tests := []struct {
+		data string
+		want []report.SmsUp
+	}{
+		// One message.
+		{
+			`{"dest_code":"1234","phone_number":"13800138000","send_time":"2017-01-01 11:12:13","sign_name":"阿里云短信测试专用","sequence_id":1234567890,"content":"退订"}`,
+			[]report.SmsUp{
+				{PhoneNumber: "13800138000", SignName: "阿里云短信测试专用", SendTime: "2017-01-01 11:12:13", SequenceID: 1234567890, Content: "退订", DestCode: "1234"},
+			},
+		},
+		// An array of messages.
+		{
+			` [{"dest_code":"1234","phone_number":"13800138000","send_time":"2017-01-01 11:12:13","sign_name":"阿里云短信测试专用","sequence_id":1234567890,"content":"退订"},
+			{"dest_code":"","phone_number":"13900139000","send_time":"2017-01-01 11:12:14","sign_name":"阿里云短信测试专用","sequence_id":1234567891,"content":"Y"}]`,
+			[]report.SmsUp{
+				{PhoneNumber: "13800138000", SignName: "阿里云短信测试专用", SendTime: "2017-01-01 11:12:13", SequenceID: 1234567890, Content: "退订", DestCode: "1234"},
+				{PhoneNumber: "13900139000", SignName: "阿里云短信测试专用", SendTime: "2017-01-01 11:12:14", SequenceID: 1234567891, Content: "Y"},
+			},
+		},
+		// Empty array.
+		{`[]`, []report.SmsUp{}},
+	}
+
+	for _, tt := range tests {
+		ups, err := report.ParseSmsUp([]byte(tt.data))
+		if err != nil {
+			t.Errorf("ParseSmsUp(%s) error: %v", tt.data, err)
+			continue
+		}
+		if len(ups) != len(tt.want) {
+			t.Errorf("ParseSmsUp(%s) = %+v, want %+v", tt.data, ups, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if ups[i] != tt.want[i] {
+				t.Errorf("ParseSmsUp(%s)[%d] = %+v, want %+v", tt.data, i, ups[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseSmsUpInvalid(t *testing.T) {
+	for _, data := range []string{``, `{"phone_number":`, `{"sequence_id":"abc"}`} {
+		if _, err := report.ParseSmsUp([]byte(data)); err == nil {
+			t.Errorf("ParseSmsUp(%q) error = nil, want error", data)
+		}
+	}
+}
+
+func TestParseSmsReport(t *testing.T) {
+	data := `[{"phone_number":"13800138000","send_time":"2017-01-01 11:12:13","report_time":"2017-01-01 11:12:15","success":true,"err_code":"DELIVERED","err_msg":"用户接收成功","sms_size":"1","biz_id":"134523^4351232","out_id":"123"},
+	{"phone_number":"13900139000","send_time":"2017-01-01 11:12:13","report_time":"2017-01-01 11:12:20","success":false,"err_code":"MK:0001","err_msg":"用户关机","sms_size":"2","biz_id":"134523^4351233","out_id":""}]`
+
+	want := []report.SmsReport{
+		{PhoneNumber: "13800138000", SendTime: "2017-01-01 11:12:13", ReportTime: "2017-01-01 11:12:15", Success: true, ErrCode: "DELIVERED", ErrMsg: "用户接收成功", SmsSize: "1", BizID: "134523^4351232", OutID: "123"},
+		{PhoneNumber: "13900139000", SendTime: "2017-01-01 11:12:13", ReportTime: "2017-01-01 11:12:20", Success: false, ErrCode: "MK:0001", ErrMsg: "用户关机", SmsSize: "2", BizID: "134523^4351233"},
+	}
+
+	reports, err := report.ParseSmsReport([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseSmsReport() error: %v", err)
+	}
+	if len(reports) != len(want) {
+		t.Fatalf("ParseSmsReport() = %+v, want %+v", reports, want)
+	}
+	for i := range want {
+		if reports[i] != want[i] {
+			t.Errorf("ParseSmsReport()[%d] = %+v, want %+v", i, reports[i], want[i])
+		}
+	}
+
+	// One message.
+	reports, err = report.ParseSmsReport([]byte(`{"phone_number":"13800138000","success":true,"err_code":"DELIVERED","biz_id":"134523^4351232"}`))
+	if err != nil || len(reports) != 1 || !reports[0].Success || reports[0].BizID != "134523^4351232" {
+		t.Errorf("ParseSmsReport() = %+v, %v, want one delivered report", reports, err)
+	}
+
+	// success must be a JSON bool.
+	if _, err := report.ParseSmsReport([]byte(`{"success":"true"}`)); err == nil {
+		t.Errorf("ParseSmsReport() error = nil, want error")
+	}
+}
+
+func TestRouteSmsUp(t *testing.T) {
+	// Campaigns keyed by their extend codes.
+	campaigns := map[string]string{"1234": "spring-sale", "5678": "survey"}
+
+	// Send SMS of campaigns with their extend codes.
+	sent := map[string]bool{}
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent[req.URL.Query().Get("SmsUpExtendCode")] = true
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"Code":"OK"}`)),
+		}, nil
+	})
+
+	for code := range campaigns {
+		if ok, _, err := client.SendSMS([]string{"13800138000", "13900139000"}, "阿里云短信测试专用", "SMS_0000", `{"code":"1234"}`, message.SmsUpExtendCode(code)); !ok || err != nil {
+			t.Fatalf("SendSMS() = %v, %v, want OK", ok, err)
+		}
+		if !sent[code] {
+			t.Errorf("SmsUpExtendCode %v is not sent", code)
+		}
+	}
+
+	// Replies carry the extend codes.
+	data := `[{"dest_code":"1234","phone_number":"13800138000","send_time":"2017-01-01 11:12:13","sign_name":"阿里云短信测试专用","sequence_id":1,"content":"Y"},
+	{"dest_code":"5678","phone_number":"13900139000","send_time":"2017-01-01 11:12:14","sign_name":"阿里云短信测试专用","sequence_id":2,"content":"5"},
+	{"dest_code":"1234","phone_number":"13900139000","send_time":"2017-01-01 11:12:15","sign_name":"阿里云短信测试专用","sequence_id":3,"content":"N"},
+	{"dest_code":"","phone_number":"13700137000","send_time":"2017-01-01 11:12:16","sign_name":"阿里云短信测试专用","sequence_id":4,"content":"退订"}]`
+	ups, err := report.ParseSmsUp([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseSmsUp() error: %v", err)
+	}
+
+	routed, unmatched := report.RouteSmsUp(ups, campaigns)
+	sequences := func(ups []report.SmsUp) string {
+		ids := []string{}
+		for _, up := range ups {
+			ids = append(ids, fmt.Sprint(up.SequenceID))
+		}
+		return strings.Join(ids, ",")
+	}
+
+	for campaign, want := range map[string]string{"spring-sale": "1,3", "survey": "2"} {
+		if got := sequences(routed[campaign]); got != want {
+			t.Errorf("sequence IDs of replies to %v = %v, want %v", campaign, got, want)
+		}
+	}
+	if len(routed) != 2 {
+		t.Errorf("len(routed) = %v, want 2", len(routed))
+	}
+	if got := sequences(unmatched); got != "4" {
+		t.Errorf("sequence IDs of unmatched replies = %v, want 4", got)
+	}
+}
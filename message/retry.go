@@ -0,0 +1,220 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/northbright/aliyun/internal/pop"
+)
+
+// retryPolicy is the policy to retry requests which are throttled or failed by server errors.
+type retryPolicy struct {
+	// maxAttempts is the max number of attempts including the first one.
+	maxAttempts int
+	// baseDelay is the delay before the first retry. It doubles for each retry.
+	baseDelay time.Duration
+}
+
+// apiResponse is implemented by all responses which embed Response.
+type apiResponse interface {
+	common() *Response
+}
+
+// common returns the common response.
+func (r *Response) common() *Response {
+	return r
+}
+
+// callResult is the result of call().
+type callResult struct {
+	// ok is true if the status code of the response is "OK".
+	ok bool
+	// parsed is true if the response is parsed.
+	parsed bool
+	// body is the raw body of the HTTP response if it's received.
+	body []byte
+}
+
+// call makes the request by do() and checks the response.
+// It retries throttled requests and server errors by the retry policy of the client.
+// The timestamp and the nonce are regenerated for each retry unless they're specified by params.
+// The timeout specified by Timeout() covers all attempts.
+// An expired timestamp is retried once more with the server time if it's enabled by WithClockSkew() and it's not specified by params.
+// A rejected signature method is retried once more with the other method if it's enabled by WithSignatureFallback() and it's not specified by params.
+func (c *Client) call(ctx context.Context, host string, v url.Values, o *requestOptions, response apiResponse) (callResult, error) {
+	if o.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.timeout)
+		defer cancel()
+	}
+
+	fallback := c.signatureFallback && !o.fixedSignatureMethod
+	if fallback {
+		v.Set("SignatureMethod", c.signatureMethod.Load().(string))
+	}
+
+	expired, synced, switched := false, false, false
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 {
+			if err := c.sleepBackoff(ctx, c.retry.backoff(attempt-1)); err != nil {
+				return callResult{}, err
+			}
+			c.refreshCommonParams(v, o, expired)
+
+			// Clear the response of the previous attempt.
+			r := reflect.ValueOf(response).Elem()
+			r.Set(reflect.Zero(r.Type()))
+		}
+
+		resp, body, err := c.do(ctx, host, v, o, response)
+		result := callResult{parsed: err == nil, body: body}
+
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+			r := response.common()
+			r.HTTPStatusCode = resp.StatusCode
+			r.AcsRequestID = resp.Header.Get("X-Acs-Request-Id")
+			r.raw = body
+		}
+
+		if result.parsed {
+			c.logResponse(ctx, v, response.common())
+			result.ok, err = c.checkResponse(response.common())
+		}
+
+		expired = isTimestampExpired(err)
+		// Retry once with the server time for the expired timestamp even if the retry policy does not.
+		if expired && !synced && !o.fixedTimestamp && c.syncServerTime(resp) {
+			synced = true
+			continue
+		}
+
+		// Retry once with the other signature method and keep it for later requests if it works.
+		if fallback && !switched && isSignatureMethodRejected(err) {
+			switched = true
+			v.Set("SignatureMethod", otherSignatureMethod(v.Get("SignatureMethod")))
+			continue
+		}
+		if switched && result.ok {
+			c.signatureMethod.Store(v.Get("SignatureMethod"))
+		}
+
+		if attempt >= c.retry.attempts() || !retryable(ctx, o, statusCode, err) {
+			return result, err
+		}
+	}
+}
+
+// refreshCommonParams regenerates the timestamp and the nonce which are not specified by params.
+// The nonce is also regenerated if the timestamp of the previous attempt is expired.
+// A timestamp specified by params is always kept.
+func (c *Client) refreshCommonParams(v url.Values, o *requestOptions, expired bool) {
+	if !o.fixedTimestamp {
+		v.Set("Timestamp", GenTimestamp(c.now()))
+	}
+	if !o.fixedNonce || expired {
+		v.Set("SignatureNonce", c.nonce())
+	}
+}
+
+// retryable reports if the request should be retried.
+// Only throttled requests and server errors are retried.
+// Network errors and expired timestamps are also retried for idempotent requests.
+// Expired timestamps specified by params are never retried.
+func retryable(ctx context.Context, o *requestOptions, statusCode int, err error) bool {
+	if statusCode >= http.StatusInternalServerError || IsThrottled(err) {
+		return true
+	}
+	if !o.idempotent {
+		return false
+	}
+	return isNetworkError(ctx, err) || (isTimestampExpired(err) && !o.fixedTimestamp)
+}
+
+// isNetworkError reports whether the error is a transport error of the HTTP request.
+// Errors caused by the context are excluded.
+func isNetworkError(ctx context.Context, err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && ctx.Err() == nil
+}
+
+// isTimestampExpired reports whether the error is an *APIError of the expired timestamp.
+func isTimestampExpired(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == CodeInvalidTimeStampExpired
+}
+
+// isSignatureMethodRejected reports whether the error is an *APIError of the signature method rejected by aliyun.
+func isSignatureMethodRejected(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == CodeInvalidSignatureMethod
+}
+
+// otherSignatureMethod returns the signature method to fall back to.
+func otherSignatureMethod(m string) string {
+	if m == pop.HMACSHA256 {
+		return pop.HMACSHA1
+	}
+	return pop.HMACSHA256
+}
+
+// attempts returns the max number of attempts. It's at least 1.
+func (p retryPolicy) attempts() int {
+	if p.maxAttempts < 1 {
+		return 1
+	}
+	return p.maxAttempts
+}
+
+// backoff returns the delay before the n-th retry.
+// It's exponential with jitter in [d/2, d) where d is baseDelay * 2^(n-1).
+func (p retryPolicy) backoff(n int) time.Duration {
+	d := p.baseDelay << uint(n-1)
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// ErrClientClosed is returned by requests whose retries are canceled by Close().
+var ErrClientClosed = errors.New("client is closed")
+
+// sleepBackoff sleeps for the backoff delay of a retry. It's counted by InFlightRetries().
+// It's interrupted when the context is done or the client is closed.
+func (c *Client) sleepBackoff(ctx context.Context, d time.Duration) error {
+	atomic.AddInt32(&c.inFlightRetries, 1)
+	defer atomic.AddInt32(&c.inFlightRetries, -1)
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return ErrClientClosed
+	}
+}
+
+// sleepContext sleeps for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
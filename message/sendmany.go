@@ -0,0 +1,82 @@
+package message
+
+import (
+	"context"
+	"sync"
+)
+
+// SendJob is the job to send the SMS by SendMany().
+type SendJob struct {
+	// PhoneNumbers are the phone numbers to send the SMS.
+	PhoneNumbers []string
+	// SignName is the permitted signature name.
+	SignName string
+	// TemplateCode is the permitted template code.
+	TemplateCode string
+	// TemplateParam is the JSON to render the template. e.g. {"code":"1234"}.
+	TemplateParam string
+	// Params are optional parameters for sending the SMS.
+	Params []Param
+}
+
+// SendResult is the result of the SendJob.
+type SendResult struct {
+	// OK is the success status.
+	OK bool
+	// Response is the response. It's nil if the response can't be parsed.
+	Response *SMSResponse
+	// Err is the error of sending the SMS.
+	Err error
+}
+
+// SendMany sends the SMS of jobs concurrently by a pool of workers.
+//
+// concurrency: max number of concurrent requests. It's 1 if it's less than 1.
+//
+// It returns results of jobs in the same order of jobs.
+// Jobs which are not started when the context is canceled get the error of the context.
+// Requests are also limited by WithMaxConcurrency() of the client.
+func (c *Client) SendMany(ctx context.Context, jobs []SendJob, concurrency int) []SendResult {
+	results := make([]SendResult, len(jobs))
+	runPool(len(jobs), concurrency, func(i int) {
+		if err := ctx.Err(); err != nil {
+			results[i] = SendResult{Err: err}
+			return
+		}
+
+		job := jobs[i]
+		ok, resp, err := c.SendSMSContext(ctx, job.PhoneNumbers, job.SignName, job.TemplateCode, job.TemplateParam, job.Params...)
+		results[i] = SendResult{OK: ok, Response: resp, Err: err}
+	})
+	return results
+}
+
+// runPool runs the jobs of indexes 0 to n-1 by a pool of workers and waits for all of them.
+// The number of workers is at most concurrency. It's 1 if concurrency is less than 1.
+// The jobs should check the context themselves to skip the rest after it's canceled.
+func runPool(n, concurrency int, run func(i int)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	indexes := make(chan int, n)
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				run(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
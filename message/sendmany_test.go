@@ -0,0 +1,89 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestSendMany(t *testing.T) {
+	var running, maxRunning int32
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		// Echo the phone number as the biz ID.
+		phoneNumber := req.URL.Query().Get("PhoneNumbers")
+		if phoneNumber == "13800138003" {
+			return newStubResponse(http.StatusOK, `{"Code":"isv.MOBILE_NUMBER_ILLEGAL"}`), nil
+		}
+		return newStubResponse(http.StatusOK, fmt.Sprintf(`{"Code":"OK","BizId":"%s"}`, phoneNumber)), nil
+	})
+
+	jobs := []message.SendJob{}
+	for i := 0; i < 10; i++ {
+		jobs = append(jobs, message.SendJob{
+			PhoneNumbers:  []string{fmt.Sprintf("1380013800%d", i)},
+			SignName:      "my_product",
+			TemplateCode:  "SMS_0000",
+			TemplateParam: `{"code":"1234"}`,
+		})
+	}
+
+	results := client.SendMany(context.Background(), jobs, 3)
+	if len(results) != len(jobs) {
+		t.Fatalf("len(results) = %v, want %v", len(results), len(jobs))
+	}
+	for i, r := range results {
+		phoneNumber := jobs[i].PhoneNumbers[0]
+		if phoneNumber == "13800138003" {
+			var apiErr *message.APIError
+			if r.OK || !errors.As(r.Err, &apiErr) {
+				t.Errorf("results[%d] = %v, %v, want *message.APIError", i, r.OK, r.Err)
+			}
+			continue
+		}
+		if !r.OK || r.Err != nil || r.Response.BizID != phoneNumber {
+			t.Errorf("results[%d] = %v, %v, %v, want OK response of %v", i, r.OK, r.Response, r.Err, phoneNumber)
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("max concurrent requests = %v, want <= 3", maxRunning)
+	}
+}
+
+func TestSendManyCanceled(t *testing.T) {
+	var requests int32
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&requests, 1)
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	jobs := make([]message.SendJob, 5)
+	for i, r := range client.SendMany(ctx, jobs, 2) {
+		if r.OK || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d] = %v, %v, want context.Canceled", i, r.OK, r.Err)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("requests = %v, want 0", requests)
+	}
+}
@@ -0,0 +1,269 @@
+package message
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Sources of the signature name for AddSmsSign().
+const (
+	// SignSourceEnterprise is the full name or short name of the enterprise or institution.
+	SignSourceEnterprise = "0"
+	// SignSourceWebsite is the full name or short name of the ICP-filed website.
+	SignSourceWebsite = "1"
+	// SignSourceApp is the full name or short name of the app.
+	SignSourceApp = "2"
+	// SignSourceOfficialAccount is the full name or short name of the official account or mini program.
+	SignSourceOfficialAccount = "3"
+	// SignSourceStore is the full name or short name of the e-commerce store.
+	SignSourceStore = "4"
+	// SignSourceTrademark is the full name or short name of the trademark.
+	SignSourceTrademark = "5"
+)
+
+// Audit statuses of the signature name and the template.
+const (
+	// AuditStatusAuditing is the status under audit.
+	AuditStatusAuditing = 0
+	// AuditStatusApproved is the status approved.
+	AuditStatusApproved = 1
+	// AuditStatusRejected is the status rejected. See the reason.
+	AuditStatusRejected = 2
+)
+
+// Audit states of signature names and templates in the results of QuerySmsSignList() and QuerySmsTemplateList().
+const (
+	// AuditStateInit is the state under audit.
+	AuditStateInit = "AUDIT_STATE_INIT"
+	// AuditStatePass is the state approved.
+	AuditStatePass = "AUDIT_STATE_PASS"
+	// AuditStateNotPass is the state rejected. See the reason.
+	AuditStateNotPass = "AUDIT_STATE_NOT_PASS"
+	// AuditStateCancel is the state canceled.
+	AuditStateCancel = "AUDIT_STATE_CANCEL"
+)
+
+// AuditReason is the reason of the rejection in the results of QuerySmsSignList() and QuerySmsTemplateList().
+type AuditReason struct {
+	// RejectDate is the time of the rejection. e.g. "2019-01-08 16:44:13".
+	RejectDate string `json:"RejectDate" xml:"RejectDate"`
+	// RejectInfo is the reason of the rejection.
+	RejectInfo string `json:"RejectInfo" xml:"RejectInfo"`
+	// RejectSubInfo is the details of the rejection.
+	RejectSubInfo string `json:"RejectSubInfo" xml:"RejectSubInfo"`
+}
+
+// SmsSignResponse is the response of HTTP request of adding or deleting the signature name.
+type SmsSignResponse struct {
+	Response
+	// SignName is the signature name.
+	SignName string `json:"SignName" xml:"SignName"`
+}
+
+// QuerySmsSignResponse is the response of HTTP request of querying the signature name.
+type QuerySmsSignResponse struct {
+	Response
+	// SignName is the signature name.
+	SignName string `json:"SignName" xml:"SignName"`
+	// SignStatus is the audit status: AuditStatusAuditing, AuditStatusApproved or AuditStatusRejected.
+	SignStatus int `json:"SignStatus" xml:"SignStatus"`
+	// Reason is the reason of the rejection.
+	Reason string `json:"Reason" xml:"Reason"`
+	// CreateDate is the time of submitting. e.g. "2019-01-08 16:44:10".
+	CreateDate string `json:"CreateDate" xml:"CreateDate"`
+}
+
+// SmsSign is the signature name in the results of QuerySmsSignList().
+type SmsSign struct {
+	// SignName is the signature name.
+	SignName string `json:"SignName" xml:"SignName"`
+	// AuditStatus is the audit state. e.g. AuditStatePass, AuditStateNotPass.
+	AuditStatus string `json:"AuditStatus" xml:"AuditStatus"`
+	// Reason is the reason of the rejection.
+	Reason AuditReason `json:"Reason" xml:"Reason"`
+	// BusinessType is the type of the signature name. e.g. "验证码类型".
+	BusinessType string `json:"BusinessType" xml:"BusinessType"`
+	// OrderID is the ID of the audit order.
+	OrderID string `json:"OrderId" xml:"OrderId"`
+	// CreateDate is the time of submitting. e.g. "2019-01-08 16:44:10".
+	CreateDate string `json:"CreateDate" xml:"CreateDate"`
+}
+
+// QuerySmsSignListResponse is the response of HTTP request of querying the signature names.
+type QuerySmsSignListResponse struct {
+	Response
+	// SmsSignList are the signature names of current page.
+	SmsSignList []SmsSign `json:"SmsSignList" xml:"SmsSignList"`
+	// TotalCount is the total count of the signature names of all pages.
+	TotalCount int `json:"TotalCount" xml:"TotalCount"`
+	// CurrentPage is the page number of current page.
+	CurrentPage int `json:"CurrentPage" xml:"CurrentPage"`
+	// PageSize is the page size.
+	PageSize int `json:"PageSize" xml:"PageSize"`
+}
+
+// signFile is the qualification document in "SignFileList".
+type signFile struct {
+	// FileContents is the base64 encoded file contents.
+	FileContents string `json:"FileContents"`
+	// FileSuffix is the file suffix. e.g. "jpg", "png", "pdf".
+	FileSuffix string `json:"FileSuffix"`
+}
+
+// signFileSuffixes maps the content types of qualification documents to file suffixes.
+var signFileSuffixes = map[string]string{
+	"image/jpeg":      "jpg",
+	"image/png":       "png",
+	"image/gif":       "gif",
+	"application/pdf": "pdf",
+}
+
+// AddSmsSign applies for the signature name.
+//
+// signName: signature name. e.g. "my_product".
+// source: source of the signature name. e.g. SignSourceEnterprise, SignSourceApp.
+// remark: description of the application for the audit.
+// fileContents: contents of qualification documents. JPG, PNG, GIF and PDF are supported.
+// params: optional parameters for adding the signature name.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+// Use QuerySmsSignStatus() to get the audit status.
+func (c *Client) AddSmsSign(signName, source, remark string, fileContents [][]byte, params ...Param) (bool, *SmsSignResponse, error) {
+	return c.AddSmsSignContext(context.Background(), signName, source, remark, fileContents, params...)
+}
+
+// AddSmsSignContext applies for the signature name with the context.
+// See AddSmsSign() for other parameters.
+func (c *Client) AddSmsSignContext(ctx context.Context, signName, source, remark string, fileContents [][]byte, params ...Param) (bool, *SmsSignResponse, error) {
+	// Qualification documents are base64 encoded.
+	files := make([]signFile, len(fileContents))
+	for i, contents := range fileContents {
+		contentType := http.DetectContentType(contents)
+		suffix, ok := signFileSuffixes[contentType]
+		if !ok {
+			return false, nil, fmt.Errorf("unsupported file type at %d: %s", i, contentType)
+		}
+		files[i] = signFile{
+			FileContents: base64.StdEncoding.EncodeToString(contents),
+			FileSuffix:   suffix,
+		}
+	}
+	fileList, err := json.Marshal(files)
+	if err != nil {
+		return false, nil, err
+	}
+
+	extra := map[string]string{
+		"SignName":     signName,
+		"SignSource":   source,
+		"Remark":       remark,
+		"SignFileList": string(fileList),
+	}
+
+	response := &SmsSignResponse{}
+	result, err := c.callAction(ctx, "AddSmsSign", extra, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// DeleteSmsSign deletes the signature name.
+// Only the signature names which are rejected or under audit can be deleted.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) DeleteSmsSign(signName string, params ...Param) (bool, *SmsSignResponse, error) {
+	return c.DeleteSmsSignContext(context.Background(), signName, params...)
+}
+
+// DeleteSmsSignContext deletes the signature name with the context.
+// See DeleteSmsSign() for other parameters.
+func (c *Client) DeleteSmsSignContext(ctx context.Context, signName string, params ...Param) (bool, *SmsSignResponse, error) {
+	response := &SmsSignResponse{}
+	result, err := c.callAction(ctx, "DeleteSmsSign", map[string]string{"SignName": signName}, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// QuerySmsSignStatus queries the audit status of the signature name.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+// The audit status is in SignStatus of the response and the reason of the rejection is in Reason.
+func (c *Client) QuerySmsSignStatus(signName string, params ...Param) (bool, *QuerySmsSignResponse, error) {
+	return c.QuerySmsSignStatusContext(context.Background(), signName, params...)
+}
+
+// QuerySmsSignStatusContext queries the audit status of the signature name with the context.
+// See QuerySmsSignStatus() for other parameters.
+func (c *Client) QuerySmsSignStatusContext(ctx context.Context, signName string, params ...Param) (bool, *QuerySmsSignResponse, error) {
+	response := &QuerySmsSignResponse{}
+	result, err := c.callAction(ctx, "QuerySmsSign", map[string]string{"SignName": signName}, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// QuerySmsSignList queries the signature names of the account and their audit states.
+//
+// pageIndex: page number of the results. It begins from 1.
+// pageSize: page size of the results. Range: 1 - 50.
+// params: optional parameters for querying the signature names.
+//
+// It returns the signature names of the page, the total count of all pages and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) QuerySmsSignList(pageIndex, pageSize int, params ...Param) ([]SmsSign, int, error) {
+	return c.QuerySmsSignListContext(context.Background(), pageIndex, pageSize, params...)
+}
+
+// QuerySmsSignListContext queries the signature names of the account with the context.
+// See QuerySmsSignList() for other parameters.
+func (c *Client) QuerySmsSignListContext(ctx context.Context, pageIndex, pageSize int, params ...Param) ([]SmsSign, int, error) {
+	extra := map[string]string{
+		"PageIndex": strconv.Itoa(pageIndex),
+		"PageSize":  strconv.Itoa(pageSize),
+	}
+
+	response := &QuerySmsSignListResponse{}
+	result, err := c.callAction(ctx, "QuerySmsSignList", extra, params, response)
+	if !result.ok {
+		return nil, 0, err
+	}
+	return response.SmsSignList, response.TotalCount, nil
+}
+
+// QueryAllSmsSigns queries the signature names of all pages.
+// Pages are queried in order until the total count is exhausted with a small delay between pages to avoid throttling.
+// It stops when the context is canceled.
+//
+// It returns the signature names of all pages in order and error.
+// The error is an *APIError if the status code of any response is not "OK".
+func (c *Client) QueryAllSmsSigns(ctx context.Context, params ...Param) ([]SmsSign, error) {
+	signs := []SmsSign{}
+	for page := 1; ; page++ {
+		if page > 1 {
+			if err := sleepContext(ctx, queryAllPageDelay); err != nil {
+				return nil, err
+			}
+		}
+
+		list, total, err := c.QuerySmsSignListContext(ctx, page, queryAllPageSize, params...)
+		if err != nil {
+			return nil, err
+		}
+
+		signs = append(signs, list...)
+		if len(list) == 0 || len(signs) >= total {
+			return signs, nil
+		}
+	}
+}
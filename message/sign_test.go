@@ -0,0 +1,218 @@
+package message_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestAddSmsSign(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"RequestId":"F655A8D5-B967-440B-8683-DAD6FF8DE990","Code":"OK","Message":"OK","SignName":"my_product"}`), nil
+	})
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR")
+	pdf := []byte("%PDF-1.4\n")
+	ok, resp, err := client.AddSmsSign("my_product", message.SignSourceApp, "app of my company", [][]byte{png, pdf})
+	if !ok || err != nil || resp.SignName != "my_product" {
+		t.Fatalf("AddSmsSign() = %v, %v, %v, want OK response", ok, resp, err)
+	}
+
+	for key, want := range map[string]string{
+		"Action":     "AddSmsSign",
+		"SignName":   "my_product",
+		"SignSource": "2",
+		"Remark":     "app of my company",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%v = %v, want %v", key, got, want)
+		}
+	}
+
+	var files []struct{ FileContents, FileSuffix string }
+	if err := json.Unmarshal([]byte(query.Get("SignFileList")), &files); err != nil {
+		t.Fatalf("SignFileList = %v, error: %v", query.Get("SignFileList"), err)
+	}
+	want := []struct{ FileContents, FileSuffix string }{
+		{base64.StdEncoding.EncodeToString(png), "png"},
+		{base64.StdEncoding.EncodeToString(pdf), "pdf"},
+	}
+	if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+		t.Errorf("SignFileList = %v, want %v", files, want)
+	}
+}
+
+func TestAddSmsSignUnsupportedFile(t *testing.T) {
+	requests := 0
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requests++
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	if _, _, err := client.AddSmsSign("my_product", message.SignSourceApp, "", [][]byte{[]byte("plain text")}); err == nil {
+		t.Errorf("AddSmsSign() with text file returns no error")
+	}
+	if requests != 0 {
+		t.Errorf("requests = %v, want 0", requests)
+	}
+}
+
+func TestDeleteSmsSign(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"RequestId":"F655A8D5-B967-440B-8683-DAD6FF8DE990","Code":"OK","Message":"OK","SignName":"my_product"}`), nil
+	})
+
+	ok, resp, err := client.DeleteSmsSign("my_product")
+	if !ok || err != nil || resp.SignName != "my_product" {
+		t.Fatalf("DeleteSmsSign() = %v, %v, %v, want OK response", ok, resp, err)
+	}
+	if query.Get("Action") != "DeleteSmsSign" || query.Get("SignName") != "my_product" {
+		t.Errorf("Action = %v, SignName = %v, want DeleteSmsSign, my_product", query.Get("Action"), query.Get("SignName"))
+	}
+}
+
+func TestQuerySmsSignStatus(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"RequestId":"F655A8D5-B967-440B-8683-DAD6FF8DE990","Code":"OK","Message":"OK","SignName":"my_product","SignStatus":2,"Reason":"文件不能证明信息真实性，请重新上传","CreateDate":"2019-01-08 16:44:10"}`},
+		{"XML", `<QuerySmsSignResponse><RequestId>F655A8D5-B967-440B-8683-DAD6FF8DE990</RequestId><Code>OK</Code><Message>OK</Message><SignName>my_product</SignName><SignStatus>2</SignStatus><Reason>文件不能证明信息真实性，请重新上传</Reason><CreateDate>2019-01-08 16:44:10</CreateDate></QuerySmsSignResponse>`},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.QuerySmsSignStatus("my_product", message.Format(tt.format))
+		if !ok || err != nil {
+			t.Fatalf("QuerySmsSignStatus() = %v, %v, %v, want OK response", ok, resp, err)
+		}
+		if query.Get("Action") != "QuerySmsSign" || query.Get("SignName") != "my_product" {
+			t.Errorf("Action = %v, SignName = %v, want QuerySmsSign, my_product", query.Get("Action"), query.Get("SignName"))
+		}
+
+		want := message.QuerySmsSignResponse{
+			SignName:   "my_product",
+			SignStatus: message.AuditStatusRejected,
+			Reason:     "文件不能证明信息真实性，请重新上传",
+			CreateDate: "2019-01-08 16:44:10",
+		}
+		if resp.SignName != want.SignName || resp.SignStatus != want.SignStatus || resp.Reason != want.Reason || resp.CreateDate != want.CreateDate {
+			t.Errorf("QuerySmsSignStatus() with format %v = %+v, want %+v", tt.format, resp, want)
+		}
+	}
+}
+
+func TestQuerySmsSignList(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"RequestId":"0A974B78-02BF-4C79-ADF3-90CFBA1B55B1","Code":"OK","Message":"OK","TotalCount":2,"CurrentPage":1,"PageSize":10,"SmsSignList":[{"SignName":"阿里云","AuditStatus":"AUDIT_STATE_PASS","BusinessType":"验证码类型","OrderId":"2004417****","CreateDate":"2019-01-08 16:44:10","Reason":{}},{"SignName":"阿里云短信","AuditStatus":"AUDIT_STATE_NOT_PASS","BusinessType":"通用类型","OrderId":"2004418****","CreateDate":"2019-01-08 16:45:10","Reason":{"RejectDate":"2019-01-08 17:44:13","RejectInfo":"资质不符","RejectSubInfo":"证明文件不清晰"}}]}`},
+		{"XML", `<QuerySmsSignListResponse><RequestId>0A974B78-02BF-4C79-ADF3-90CFBA1B55B1</RequestId><Code>OK</Code><Message>OK</Message><TotalCount>2</TotalCount><CurrentPage>1</CurrentPage><PageSize>10</PageSize><SmsSignList><SignName>阿里云</SignName><AuditStatus>AUDIT_STATE_PASS</AuditStatus><BusinessType>验证码类型</BusinessType><OrderId>2004417****</OrderId><CreateDate>2019-01-08 16:44:10</CreateDate><Reason></Reason></SmsSignList><SmsSignList><SignName>阿里云短信</SignName><AuditStatus>AUDIT_STATE_NOT_PASS</AuditStatus><BusinessType>通用类型</BusinessType><OrderId>2004418****</OrderId><CreateDate>2019-01-08 16:45:10</CreateDate><Reason><RejectDate>2019-01-08 17:44:13</RejectDate><RejectInfo>资质不符</RejectInfo><RejectSubInfo>证明文件不清晰</RejectSubInfo></Reason></SmsSignList></QuerySmsSignListResponse>`},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		signs, total, err := client.QuerySmsSignList(1, 10, message.Format(tt.format))
+		if err != nil {
+			t.Fatalf("QuerySmsSignList() error: %v", err)
+		}
+		for key, want := range map[string]string{
+			"Action":    "QuerySmsSignList",
+			"PageIndex": "1",
+			"PageSize":  "10",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("%v = %v, want %v", key, got, want)
+			}
+		}
+
+		if total != 2 || len(signs) != 2 {
+			t.Fatalf("QuerySmsSignList() = %v signs, total %v, want 2, 2", len(signs), total)
+		}
+		if signs[0].SignName != "阿里云" || signs[0].AuditStatus != message.AuditStatePass || signs[0].OrderID != "2004417****" {
+			t.Errorf("signs[0] = %+v, want approved 阿里云", signs[0])
+		}
+		want := message.AuditReason{RejectDate: "2019-01-08 17:44:13", RejectInfo: "资质不符", RejectSubInfo: "证明文件不清晰"}
+		if signs[1].AuditStatus != message.AuditStateNotPass || signs[1].Reason != want {
+			t.Errorf("signs[1] = %+v, want rejected with reason %+v", signs[1], want)
+		}
+	}
+}
+
+func TestQuerySmsSignListError(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"Code":"isv.ACCOUNT_NOT_EXISTS","Message":"account not exists"}`), nil
+	})
+
+	signs, total, err := client.QuerySmsSignList(1, 10)
+	var apiErr *message.APIError
+	if !errors.As(err, &apiErr) || signs != nil || total != 0 {
+		t.Errorf("QuerySmsSignList() = %v, %v, %v, want nil, 0, *message.APIError", signs, total, err)
+	}
+}
+
+func TestQueryAllSmsSigns(t *testing.T) {
+	pages := []string{}
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query := req.URL.Query()
+		pages = append(pages, query.Get("PageIndex"))
+		page, _ := strconv.Atoi(query.Get("PageIndex"))
+		pageSize, _ := strconv.Atoi(query.Get("PageSize"))
+
+		// 70 signature names in total.
+		list := []message.SmsSign{}
+		for i := (page - 1) * pageSize; i < page*pageSize && i < 70; i++ {
+			list = append(list, message.SmsSign{SignName: strconv.Itoa(i), AuditStatus: message.AuditStatePass})
+		}
+		buf, _ := json.Marshal(list)
+		return newStubResponse(http.StatusOK, fmt.Sprintf(`{"Code":"OK","TotalCount":70,"SmsSignList":%s}`, buf)), nil
+	})
+
+	signs, err := client.QueryAllSmsSigns(context.Background())
+	if err != nil {
+		t.Fatalf("QueryAllSmsSigns() error: %v", err)
+	}
+	if len(pages) != 2 || pages[0] != "1" || pages[1] != "2" {
+		t.Errorf("pages = %v, want [1 2]", pages)
+	}
+
+	// Signature names of all pages are in order.
+	if len(signs) != 70 {
+		t.Fatalf("len(signs) = %v, want 70", len(signs))
+	}
+	for i, sign := range signs {
+		if sign.SignName != strconv.Itoa(i) {
+			t.Errorf("signs[%d].SignName = %v, want %v", i, sign.SignName, i)
+		}
+	}
+}
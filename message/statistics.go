@@ -0,0 +1,91 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+// SmsStatistics is the statistics of SMS sent on a day.
+type SmsStatistics struct {
+	// SendDate is the date of sending SMS. e.g. "20190108".
+	SendDate string `json:"SendDate" xml:"SendDate"`
+	// TotalCount is the total count of SMS.
+	TotalCount int `json:"TotalCount" xml:"TotalCount"`
+	// RespondedSuccessCount is the count of delivered SMS.
+	RespondedSuccessCount int `json:"RespondedSuccessCount" xml:"RespondedSuccessCount"`
+	// RespondedFailCount is the count of SMS failed to deliver.
+	RespondedFailCount int `json:"RespondedFailCount" xml:"RespondedFailCount"`
+	// NoRespondedCount is the count of SMS waiting for the report.
+	NoRespondedCount int `json:"NoRespondedCount" xml:"NoRespondedCount"`
+}
+
+// QuerySmsStatisticsResponse is the response of HTTP request of querying SMS statistics.
+type QuerySmsStatisticsResponse struct {
+	Response
+	// TotalSize is the total count of days of all pages.
+	TotalSize int `json:"-" xml:"Data>TotalSize"`
+	// Statistics are the statistics of days of current page.
+	Statistics []SmsStatistics `json:"-" xml:"Data>TargetList"`
+}
+
+// UnmarshalJSON parses the JSON response.
+// Statistics are nested under "Data" > "TargetList".
+func (r *QuerySmsStatisticsResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Response
+		Data struct {
+			TotalSize  int             `json:"TotalSize"`
+			TargetList []SmsStatistics `json:"TargetList"`
+		} `json:"Data"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	r.Response = raw.Response
+	r.TotalSize = raw.Data.TotalSize
+	r.Statistics = raw.Data.TargetList
+	return nil
+}
+
+// QuerySmsStatistics queries the daily statistics of SMS sent in the date range.
+// e.g. build the daily usage report.
+//
+// isGlobe: true for international SMS and false for SMS sent in China mainland.
+// startDate: the start date in "yyyyMMdd" format. e.g. "20190101". Use GenSendDate() to generate it.
+// endDate: the end date in "yyyyMMdd" format. e.g. "20190108".
+// pageIndex: page number of the results. It begins from 1.
+// pageSize: page size of the results. Range: 1 - 50.
+// params: optional parameters for querying SMS statistics.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) QuerySmsStatistics(isGlobe bool, startDate, endDate string, pageIndex, pageSize int, params ...Param) (bool, *QuerySmsStatisticsResponse, error) {
+	return c.QuerySmsStatisticsContext(context.Background(), isGlobe, startDate, endDate, pageIndex, pageSize, params...)
+}
+
+// QuerySmsStatisticsContext queries the daily statistics of SMS with the context.
+// See QuerySmsStatistics() for other parameters.
+func (c *Client) QuerySmsStatisticsContext(ctx context.Context, isGlobe bool, startDate, endDate string, pageIndex, pageSize int, params ...Param) (bool, *QuerySmsStatisticsResponse, error) {
+	// 1: China mainland, 2: international.
+	globe := "1"
+	if isGlobe {
+		globe = "2"
+	}
+
+	extra := map[string]string{
+		"IsGlobe":   globe,
+		"StartDate": startDate,
+		"EndDate":   endDate,
+		"PageIndex": strconv.Itoa(pageIndex),
+		"PageSize":  strconv.Itoa(pageSize),
+	}
+
+	response := &QuerySmsStatisticsResponse{}
+	result, err := c.callAction(ctx, "QuerySmsStatistics", extra, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
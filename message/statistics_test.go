@@ -0,0 +1,70 @@
+package message_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestQuerySmsStatistics(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"RequestId":"819BE656-D2E0-4858-8B21-B2E477085AAF","Code":"OK","Message":"OK","Data":{"TotalSize":2,"TargetList":[{"TotalCount":100,"RespondedSuccessCount":95,"RespondedFailCount":3,"NoRespondedCount":2,"SendDate":"20190107"},{"TotalCount":50,"RespondedSuccessCount":50,"RespondedFailCount":0,"NoRespondedCount":0,"SendDate":"20190108"}]}}`},
+		{"XML", `<QuerySmsStatisticsResponse><RequestId>819BE656-D2E0-4858-8B21-B2E477085AAF</RequestId><Code>OK</Code><Message>OK</Message><Data><TotalSize>2</TotalSize><TargetList><TotalCount>100</TotalCount><RespondedSuccessCount>95</RespondedSuccessCount><RespondedFailCount>3</RespondedFailCount><NoRespondedCount>2</NoRespondedCount><SendDate>20190107</SendDate></TargetList><TargetList><TotalCount>50</TotalCount><RespondedSuccessCount>50</RespondedSuccessCount><RespondedFailCount>0</RespondedFailCount><NoRespondedCount>0</NoRespondedCount><SendDate>20190108</SendDate></TargetList></Data></QuerySmsStatisticsResponse>`},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.QuerySmsStatistics(false, "20190107", "20190108", 1, 10, message.Format(tt.format))
+		if !ok || err != nil {
+			t.Fatalf("QuerySmsStatistics() with format %v = %v, %v, %v, want OK response", tt.format, ok, resp, err)
+		}
+
+		for key, want := range map[string]string{
+			"Action":    "QuerySmsStatistics",
+			"IsGlobe":   "1",
+			"StartDate": "20190107",
+			"EndDate":   "20190108",
+			"PageIndex": "1",
+			"PageSize":  "10",
+		} {
+			if got := query.Get(key); got != want {
+				t.Errorf("%v = %v, want %v", key, got, want)
+			}
+		}
+
+		want := []message.SmsStatistics{
+			{SendDate: "20190107", TotalCount: 100, RespondedSuccessCount: 95, RespondedFailCount: 3, NoRespondedCount: 2},
+			{SendDate: "20190108", TotalCount: 50, RespondedSuccessCount: 50},
+		}
+		if resp.TotalSize != 2 || len(resp.Statistics) != 2 || resp.Statistics[0] != want[0] || resp.Statistics[1] != want[1] {
+			t.Errorf("QuerySmsStatistics() with format %v = %v, %+v, want %+v", tt.format, resp.TotalSize, resp.Statistics, want)
+		}
+	}
+}
+
+func TestQuerySmsStatisticsGlobe(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"Code":"OK","Data":{"TotalSize":0,"TargetList":[]}}`), nil
+	})
+
+	if _, _, err := client.QuerySmsStatistics(true, "20190107", "20190108", 1, 10); err != nil {
+		t.Fatalf("QuerySmsStatistics() error: %v", err)
+	}
+	if got := query.Get("IsGlobe"); got != "2" {
+		t.Errorf("IsGlobe = %v, want 2", got)
+	}
+}
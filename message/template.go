@@ -0,0 +1,205 @@
+package message
+
+import (
+	"context"
+	"strconv"
+)
+
+// Types of the template for AddSmsTemplate().
+const (
+	// TemplateTypeVerification is the type of verification code templates.
+	TemplateTypeVerification = 0
+	// TemplateTypeNotification is the type of notification templates.
+	TemplateTypeNotification = 1
+	// TemplateTypePromotion is the type of promotion templates.
+	TemplateTypePromotion = 2
+	// TemplateTypeInternational is the type of templates for international or Hong Kong, Macao and Taiwan messages.
+	TemplateTypeInternational = 3
+)
+
+// SmsTemplateResponse is the response of HTTP request of adding or deleting the template.
+type SmsTemplateResponse struct {
+	Response
+	// TemplateCode is the template code. e.g. "SMS_0000".
+	TemplateCode string `json:"TemplateCode" xml:"TemplateCode"`
+}
+
+// QuerySmsTemplateResponse is the response of HTTP request of querying the template.
+type QuerySmsTemplateResponse struct {
+	Response
+	// TemplateType is the type of the template. e.g. TemplateTypeVerification.
+	TemplateType int `json:"TemplateType" xml:"TemplateType"`
+	// TemplateCode is the template code. e.g. "SMS_0000".
+	TemplateCode string `json:"TemplateCode" xml:"TemplateCode"`
+	// TemplateName is the name of the template.
+	TemplateName string `json:"TemplateName" xml:"TemplateName"`
+	// TemplateContent is the content of the template. e.g. "您的验证码为：${code}".
+	TemplateContent string `json:"TemplateContent" xml:"TemplateContent"`
+	// TemplateStatus is the audit status: AuditStatusAuditing, AuditStatusApproved or AuditStatusRejected.
+	TemplateStatus int `json:"TemplateStatus" xml:"TemplateStatus"`
+	// Reason is the reason of the rejection.
+	Reason string `json:"Reason" xml:"Reason"`
+	// CreateDate is the time of submitting. e.g. "2019-01-08 16:44:10".
+	CreateDate string `json:"CreateDate" xml:"CreateDate"`
+}
+
+// SmsTemplate is the template in the results of QuerySmsTemplateList().
+type SmsTemplate struct {
+	// TemplateCode is the template code. e.g. "SMS_0000".
+	TemplateCode string `json:"TemplateCode" xml:"TemplateCode"`
+	// TemplateName is the name of the template.
+	TemplateName string `json:"TemplateName" xml:"TemplateName"`
+	// TemplateType is the type of the template. e.g. TemplateTypeVerification.
+	TemplateType int `json:"TemplateType" xml:"TemplateType"`
+	// TemplateContent is the content of the template. e.g. "您的验证码为：${code}".
+	TemplateContent string `json:"TemplateContent" xml:"TemplateContent"`
+	// AuditStatus is the audit state. e.g. AuditStatePass, AuditStateNotPass.
+	AuditStatus string `json:"AuditStatus" xml:"AuditStatus"`
+	// Reason is the reason of the rejection.
+	Reason AuditReason `json:"Reason" xml:"Reason"`
+	// OrderID is the ID of the audit order.
+	OrderID string `json:"OrderId" xml:"OrderId"`
+	// CreateDate is the time of submitting. e.g. "2019-01-08 16:44:10".
+	CreateDate string `json:"CreateDate" xml:"CreateDate"`
+}
+
+// QuerySmsTemplateListResponse is the response of HTTP request of querying the templates.
+type QuerySmsTemplateListResponse struct {
+	Response
+	// SmsTemplateList are the templates of current page.
+	SmsTemplateList []SmsTemplate `json:"SmsTemplateList" xml:"SmsTemplateList"`
+	// TotalCount is the total count of the templates of all pages.
+	TotalCount int `json:"TotalCount" xml:"TotalCount"`
+	// CurrentPage is the page number of current page.
+	CurrentPage int `json:"CurrentPage" xml:"CurrentPage"`
+	// PageSize is the page size.
+	PageSize int `json:"PageSize" xml:"PageSize"`
+}
+
+// AddSmsTemplate applies for the template.
+//
+// templateType: type of the template. e.g. TemplateTypeVerification, TemplateTypeNotification.
+// templateName: name of the template.
+// templateContent: content of the template with variables. e.g. "您的验证码为：${code}".
+// remark: description of the application for the audit.
+// params: optional parameters for adding the template.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+// The template code is in the response. Use QuerySmsTemplate() to get the audit status.
+func (c *Client) AddSmsTemplate(templateType int, templateName, templateContent, remark string, params ...Param) (bool, *SmsTemplateResponse, error) {
+	return c.AddSmsTemplateContext(context.Background(), templateType, templateName, templateContent, remark, params...)
+}
+
+// AddSmsTemplateContext applies for the template with the context.
+// See AddSmsTemplate() for other parameters.
+func (c *Client) AddSmsTemplateContext(ctx context.Context, templateType int, templateName, templateContent, remark string, params ...Param) (bool, *SmsTemplateResponse, error) {
+	extra := map[string]string{
+		"TemplateType":    strconv.Itoa(templateType),
+		"TemplateName":    templateName,
+		"TemplateContent": templateContent,
+		"Remark":          remark,
+	}
+
+	response := &SmsTemplateResponse{}
+	result, err := c.callAction(ctx, "AddSmsTemplate", extra, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// DeleteSmsTemplate deletes the template.
+// Only the templates which are rejected or under audit can be deleted.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) DeleteSmsTemplate(templateCode string, params ...Param) (bool, *SmsTemplateResponse, error) {
+	return c.DeleteSmsTemplateContext(context.Background(), templateCode, params...)
+}
+
+// DeleteSmsTemplateContext deletes the template with the context.
+// See DeleteSmsTemplate() for other parameters.
+func (c *Client) DeleteSmsTemplateContext(ctx context.Context, templateCode string, params ...Param) (bool, *SmsTemplateResponse, error) {
+	response := &SmsTemplateResponse{}
+	result, err := c.callAction(ctx, "DeleteSmsTemplate", map[string]string{"TemplateCode": templateCode}, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// QuerySmsTemplate queries the template and its audit status.
+//
+// It returns success status, response and error.
+// The error is an *APIError if the status code of the response is not "OK".
+// The audit status is in TemplateStatus of the response and the reason of the rejection is in Reason.
+func (c *Client) QuerySmsTemplate(templateCode string, params ...Param) (bool, *QuerySmsTemplateResponse, error) {
+	return c.QuerySmsTemplateContext(context.Background(), templateCode, params...)
+}
+
+// QuerySmsTemplateContext queries the template with the context.
+// See QuerySmsTemplate() for other parameters.
+func (c *Client) QuerySmsTemplateContext(ctx context.Context, templateCode string, params ...Param) (bool, *QuerySmsTemplateResponse, error) {
+	response := &QuerySmsTemplateResponse{}
+	result, err := c.callAction(ctx, "QuerySmsTemplate", map[string]string{"TemplateCode": templateCode}, params, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// QuerySmsTemplateList queries the templates of the account and their audit states.
+//
+// pageIndex: page number of the results. It begins from 1.
+// pageSize: page size of the results. Range: 1 - 50.
+// params: optional parameters for querying the templates.
+//
+// It returns the templates of the page, the total count of all pages and error.
+// The error is an *APIError if the status code of the response is not "OK".
+func (c *Client) QuerySmsTemplateList(pageIndex, pageSize int, params ...Param) ([]SmsTemplate, int, error) {
+	return c.QuerySmsTemplateListContext(context.Background(), pageIndex, pageSize, params...)
+}
+
+// QuerySmsTemplateListContext queries the templates of the account with the context.
+// See QuerySmsTemplateList() for other parameters.
+func (c *Client) QuerySmsTemplateListContext(ctx context.Context, pageIndex, pageSize int, params ...Param) ([]SmsTemplate, int, error) {
+	extra := map[string]string{
+		"PageIndex": strconv.Itoa(pageIndex),
+		"PageSize":  strconv.Itoa(pageSize),
+	}
+
+	response := &QuerySmsTemplateListResponse{}
+	result, err := c.callAction(ctx, "QuerySmsTemplateList", extra, params, response)
+	if !result.ok {
+		return nil, 0, err
+	}
+	return response.SmsTemplateList, response.TotalCount, nil
+}
+
+// QueryAllSmsTemplates queries the templates of all pages.
+// Pages are queried in order until the total count is exhausted with a small delay between pages to avoid throttling.
+// It stops when the context is canceled.
+//
+// It returns the templates of all pages in order and error.
+// The error is an *APIError if the status code of any response is not "OK".
+func (c *Client) QueryAllSmsTemplates(ctx context.Context, params ...Param) ([]SmsTemplate, error) {
+	templates := []SmsTemplate{}
+	for page := 1; ; page++ {
+		if page > 1 {
+			if err := sleepContext(ctx, queryAllPageDelay); err != nil {
+				return nil, err
+			}
+		}
+
+		list, total, err := c.QuerySmsTemplateListContext(ctx, page, queryAllPageSize, params...)
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, list...)
+		if len(list) == 0 || len(templates) >= total {
+			return templates, nil
+		}
+	}
+}
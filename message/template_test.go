@@ -0,0 +1,146 @@
+package message_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestAddSmsTemplate(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"RequestId":"0A974B78-02BF-4C79-ADF3-90CFBA1B55B1","Code":"OK","Message":"OK","TemplateCode":"SMS_15255****"}`), nil
+	})
+
+	ok, resp, err := client.AddSmsTemplate(message.TemplateTypeVerification, "验证码", "您的验证码为：${code}", "用户登录")
+	if !ok || err != nil || resp.TemplateCode != "SMS_15255****" {
+		t.Fatalf("AddSmsTemplate() = %v, %v, %v, want OK response", ok, resp, err)
+	}
+
+	for key, want := range map[string]string{
+		"Action":          "AddSmsTemplate",
+		"TemplateType":    "0",
+		"TemplateName":    "验证码",
+		"TemplateContent": "您的验证码为：${code}",
+		"Remark":          "用户登录",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%v = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestDeleteSmsTemplate(t *testing.T) {
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, `{"RequestId":"0A974B78-02BF-4C79-ADF3-90CFBA1B55B1","Code":"OK","Message":"OK","TemplateCode":"SMS_0000"}`), nil
+	})
+
+	ok, resp, err := client.DeleteSmsTemplate("SMS_0000")
+	if !ok || err != nil || resp.TemplateCode != "SMS_0000" {
+		t.Fatalf("DeleteSmsTemplate() = %v, %v, %v, want OK response", ok, resp, err)
+	}
+	if query.Get("Action") != "DeleteSmsTemplate" || query.Get("TemplateCode") != "SMS_0000" {
+		t.Errorf("Action = %v, TemplateCode = %v, want DeleteSmsTemplate, SMS_0000", query.Get("Action"), query.Get("TemplateCode"))
+	}
+}
+
+func TestQuerySmsTemplate(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"RequestId":"0A974B78-02BF-4C79-ADF3-90CFBA1B55B1","Code":"OK","Message":"OK","TemplateType":0,"TemplateCode":"SMS_0000","TemplateName":"验证码","TemplateContent":"您的验证码为：${code}","TemplateStatus":1,"Reason":"","CreateDate":"2019-01-08 16:44:10"}`},
+		{"XML", `<QuerySmsTemplateResponse><RequestId>0A974B78-02BF-4C79-ADF3-90CFBA1B55B1</RequestId><Code>OK</Code><Message>OK</Message><TemplateType>0</TemplateType><TemplateCode>SMS_0000</TemplateCode><TemplateName>验证码</TemplateName><TemplateContent>您的验证码为：${code}</TemplateContent><TemplateStatus>1</TemplateStatus><Reason></Reason><CreateDate>2019-01-08 16:44:10</CreateDate></QuerySmsTemplateResponse>`},
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.QuerySmsTemplate("SMS_0000", message.Format(tt.format))
+		if !ok || err != nil {
+			t.Fatalf("QuerySmsTemplate() = %v, %v, %v, want OK response", ok, resp, err)
+		}
+		if query.Get("Action") != "QuerySmsTemplate" || query.Get("TemplateCode") != "SMS_0000" {
+			t.Errorf("Action = %v, TemplateCode = %v, want QuerySmsTemplate, SMS_0000", query.Get("Action"), query.Get("TemplateCode"))
+		}
+
+		if resp.TemplateType != message.TemplateTypeVerification || resp.TemplateCode != "SMS_0000" ||
+			resp.TemplateName != "验证码" || resp.TemplateContent != "您的验证码为：${code}" ||
+			resp.TemplateStatus != message.AuditStatusApproved || resp.CreateDate != "2019-01-08 16:44:10" {
+			t.Errorf("QuerySmsTemplate() with format %v = %+v, want approved template SMS_0000", tt.format, resp)
+		}
+	}
+}
+
+func TestQuerySmsTemplateList(t *testing.T) {
+	body := `{"RequestId":"0A974B78-02BF-4C79-ADF3-90CFBA1B55B1","Code":"OK","Message":"OK","TotalCount":2,"CurrentPage":1,"PageSize":10,"SmsTemplateList":[{"TemplateCode":"SMS_0000","TemplateName":"验证码","TemplateType":0,"TemplateContent":"您的验证码为：${code}","AuditStatus":"AUDIT_STATE_PASS","OrderId":"2004417****","CreateDate":"2019-01-08 16:44:10","Reason":{}},{"TemplateCode":"SMS_0001","TemplateName":"促销","TemplateType":2,"TemplateContent":"新品上市","AuditStatus":"AUDIT_STATE_NOT_PASS","OrderId":"2004418****","CreateDate":"2019-01-08 16:45:10","Reason":{"RejectDate":"2019-01-08 17:44:13","RejectInfo":"模板内容不符合规范","RejectSubInfo":"缺少退订方式"}}]}`
+
+	var query url.Values
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		query = req.URL.Query()
+		return newStubResponse(http.StatusOK, body), nil
+	})
+
+	templates, total, err := client.QuerySmsTemplateList(1, 10)
+	if err != nil {
+		t.Fatalf("QuerySmsTemplateList() error: %v", err)
+	}
+	if query.Get("Action") != "QuerySmsTemplateList" || query.Get("PageIndex") != "1" || query.Get("PageSize") != "10" {
+		t.Errorf("Action = %v, PageIndex = %v, PageSize = %v, want QuerySmsTemplateList, 1, 10",
+			query.Get("Action"), query.Get("PageIndex"), query.Get("PageSize"))
+	}
+
+	if total != 2 || len(templates) != 2 {
+		t.Fatalf("QuerySmsTemplateList() = %v templates, total %v, want 2, 2", len(templates), total)
+	}
+	if templates[0].TemplateCode != "SMS_0000" || templates[0].TemplateType != message.TemplateTypeVerification ||
+		templates[0].TemplateContent != "您的验证码为：${code}" || templates[0].AuditStatus != message.AuditStatePass {
+		t.Errorf("templates[0] = %+v, want approved SMS_0000", templates[0])
+	}
+	want := message.AuditReason{RejectDate: "2019-01-08 17:44:13", RejectInfo: "模板内容不符合规范", RejectSubInfo: "缺少退订方式"}
+	if templates[1].AuditStatus != message.AuditStateNotPass || templates[1].Reason != want {
+		t.Errorf("templates[1] = %+v, want rejected with reason %+v", templates[1], want)
+	}
+}
+
+func TestQueryAllSmsTemplates(t *testing.T) {
+	pages := []string{}
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		page := req.URL.Query().Get("PageIndex")
+		pages = append(pages, page)
+
+		// 2 full pages and an empty page although the total count is larger.
+		if page == "3" {
+			return newStubResponse(http.StatusOK, `{"Code":"OK","TotalCount":120,"SmsTemplateList":[]}`), nil
+		}
+		list := strings.Repeat(`{"TemplateCode":"SMS_`+page+`"},`, 50)
+		return newStubResponse(http.StatusOK, `{"Code":"OK","TotalCount":120,"SmsTemplateList":[`+strings.TrimSuffix(list, ",")+`]}`), nil
+	})
+
+	templates, err := client.QueryAllSmsTemplates(context.Background())
+	if err != nil {
+		t.Fatalf("QueryAllSmsTemplates() error: %v", err)
+	}
+	if want := "1,2,3"; strings.Join(pages, ",") != want {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+	if len(templates) != 100 || templates[0].TemplateCode != "SMS_1" || templates[99].TemplateCode != "SMS_2" {
+		t.Errorf("QueryAllSmsTemplates() = %v templates, want 100 templates of pages 1 and 2", len(templates))
+	}
+}
@@ -0,0 +1,82 @@
+// Package testhelper provides helpers to test the code using message.Client without calling aliyun.
+//
+// Set the transport of the client to a stub transport. e.g.
+//
+//	t := testhelper.NewSequenceTransport(testhelper.Throttled, testhelper.Throttled, testhelper.OK)
+//	client := message.NewClient(accessKeyID, accessKeySecret)
+//	client.Transport = t
+package testhelper
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Response is the stub HTTP response returned by the transport.
+type Response struct {
+	// StatusCode is the HTTP status code.
+	StatusCode int
+	// Body is the response body. e.g. `{"Code":"OK"}`.
+	Body string
+}
+
+var (
+	// OK is the response of a successful request.
+	OK = Response{
+		StatusCode: http.StatusOK,
+		Body:       `{"RequestId":"STUB-OK","Code":"OK","Message":"OK","BizId":"000000^0"}`,
+	}
+	// Throttled is the response of a request denied by aliyun's flow control.
+	Throttled = Response{
+		StatusCode: http.StatusOK,
+		Body:       `{"RequestId":"STUB-THROTTLED","Code":"isv.BUSINESS_LIMIT_CONTROL","Message":"触发分钟级流控Permits:1"}`,
+	}
+)
+
+// SequenceTransport is a http.RoundTripper which returns the responses in sequence.
+// The last response is returned repeatedly after the sequence is exhausted.
+// It's safe for concurrent use.
+type SequenceTransport struct {
+	mu        sync.Mutex
+	responses []Response
+	requests  []*http.Request
+}
+
+// NewSequenceTransport creates a new transport which returns the responses in sequence.
+func NewSequenceTransport(responses ...Response) *SequenceTransport {
+	return &SequenceTransport{responses: responses}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *SequenceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resp := OK
+	if n := len(t.responses); n > 0 {
+		i := len(t.requests)
+		if i >= n {
+			i = n - 1
+		}
+		resp = t.responses[i]
+	}
+	t.requests = append(t.requests, req)
+
+	return &http.Response{
+		StatusCode: resp.StatusCode,
+		Status:     http.StatusText(resp.StatusCode),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(resp.Body)),
+		Request:    req,
+	}, nil
+}
+
+// Requests returns the requests received by the transport.
+func (t *SequenceTransport) Requests() []*http.Request {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*http.Request{}, t.requests...)
+}
@@ -0,0 +1,42 @@
+package testhelper_test
+
+import (
+	"testing"
+
+	"github.com/northbright/aliyun/message"
+	"github.com/northbright/aliyun/message/testhelper"
+)
+
+func TestSequenceTransport(t *testing.T) {
+	transport := testhelper.NewSequenceTransport(testhelper.Throttled, testhelper.Throttled, testhelper.OK)
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = transport
+
+	// Retry on throttling like callers' retry code.
+	codes := []string{}
+	for i := 0; i < 5; i++ {
+		ok, resp, err := client.SendSMS([]string{"13800138000"}, "my_product", "SMS_0000", `{"code":"1234"}`)
+		if err != nil && !message.IsThrottled(err) {
+			t.Fatalf("SendSMS() error: %v", err)
+		}
+		codes = append(codes, resp.Code)
+		if ok {
+			break
+		}
+	}
+
+	want := []string{"isv.BUSINESS_LIMIT_CONTROL", "isv.BUSINESS_LIMIT_CONTROL", "OK"}
+	if len(codes) != len(want) {
+		t.Fatalf("codes = %v, want %v", codes, want)
+	}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("codes = %v, want %v", codes, want)
+			break
+		}
+	}
+
+	if n := len(transport.Requests()); n != 3 {
+		t.Errorf("requests = %v, want 3", n)
+	}
+}
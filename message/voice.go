@@ -0,0 +1,278 @@
+package message
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const (
+	// ProdIDVoiceNotify is the product ID of voice notification(including TTS and voice file calls).
+	ProdIDVoiceNotify = "11000000300006"
+	// ProdIDVoiceVerification is the product ID of voice verification.
+	ProdIDVoiceVerification = "11010000138001"
+)
+
+// MakeSingleCallByVoice makes the single call to play the voice file.
+//
+// calledShowNumber: called show number to users. It can be purchased at aliyun's control panel.
+// calledNumber: phone number to make single call.
+// voiceCode: permitted voice file ID. You may upload voice files in aliyun's control panel.
+// params: optional parameters for the call. e.g. PlayTimes(), Volume(), Speed().
+//
+// It returns success status, response and error.
+// The response is the same as MakeSingleCallByTTS().
+// The error is an *APIError if the status code of the response is not "OK".
+//
+// For example:
+//
+// c := message.NewClient(accessKeyID, accessKeySecret)
+//
+// ok, resp, err := c.MakeSingleCallByVoice("02560000000", "1500000000", "2d4c-4e78-8d2a-afbb06cf****.wav")
+func (c *Client) MakeSingleCallByVoice(calledShowNumber, calledNumber, voiceCode string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
+	return c.MakeSingleCallByVoiceContext(context.Background(), calledShowNumber, calledNumber, voiceCode, params...)
+}
+
+// MakeSingleCallByVoiceContext makes the single call to play the voice file with the context.
+// The HTTP request is aborted when the context is canceled or its deadline exceeds.
+// See MakeSingleCallByVoice() for other parameters.
+func (c *Client) MakeSingleCallByVoiceContext(ctx context.Context, calledShowNumber, calledNumber, voiceCode string, params ...Param) (bool, *SingleCallByTTSResponse, error) {
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for the voice call.
+	v.Set("Action", "SingleCallByVoice")
+	v.Set("Version", voiceVersion)
+	v.Set("RegionId", DefaultRegionID)
+
+	// Set required business parameters
+	v.Set("CalledShowNumber", calledShowNumber)
+	v.Set("CalledNumber", calledNumber)
+	v.Set("VoiceCode", voiceCode)
+
+	// Override parameters if need.
+	o, err := applyParams(v, params)
+	if err != nil {
+		return false, nil, err
+	}
+
+	response := &SingleCallByTTSResponse{}
+	host, err := serviceHost(v, o, ServiceVoice)
+	if err != nil {
+		return false, nil, err
+	}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	return result.ok, response, err
+}
+
+// CallDetail is the detail of a voice call.
+type CallDetail struct {
+	// CallID is the ID of the call. e.g. "116012354148^10281378".
+	CallID string
+	// State is the state code of the call. e.g. "200000".
+	State string
+	// StateDesc is the description of the state. e.g. "用户接听".
+	StateDesc string
+	// Duration is the duration of the call in seconds.
+	Duration int
+	// StartTime is the start time of the call. e.g. "2019-01-08 16:44:35".
+	StartTime string
+	// EndTime is the end time of the call. e.g. "2019-01-08 16:45:03".
+	EndTime string
+	// Callee is the called number.
+	Callee string
+	// CalleeShowNumber is the called show number.
+	CalleeShowNumber string
+	// HangupDirection is the side which hangs up the call. e.g. "0": the callee, "1": the platform.
+	HangupDirection string
+}
+
+// QueryCallDetailResponse is the response of HTTP request of querying call detail.
+type QueryCallDetailResponse struct {
+	Response
+	// Data is the raw JSON string of the call detail.
+	Data string `json:"Data" xml:"Data"`
+	// Detail is the call detail parsed from Data. It's nil if Data is empty.
+	Detail *CallDetail `json:"-" xml:"-"`
+}
+
+// parseCallDetail parses the call detail from the JSON string of "Data".
+// aliyun encodes the detail as a JSON string inside the response but not a JSON object,
+// and "duration" as a JSON number or string, both are accepted.
+func parseCallDetail(data string) (*CallDetail, error) {
+	var raw struct {
+		CallID           string      `json:"callId"`
+		State            string      `json:"state"`
+		StateDesc        string      `json:"stateDesc"`
+		Duration         json.Number `json:"duration"`
+		StartDate        string      `json:"startDate"`
+		EndDate          string      `json:"endDate"`
+		Callee           string      `json:"callee"`
+		CalleeShowNumber string      `json:"calleeShowNumber"`
+		HangupDirection  string      `json:"hangupDirection"`
+	}
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, fmt.Errorf("invalid call detail JSON: %v", err)
+	}
+
+	detail := &CallDetail{
+		CallID:           raw.CallID,
+		State:            raw.State,
+		StateDesc:        raw.StateDesc,
+		StartTime:        raw.StartDate,
+		EndTime:          raw.EndDate,
+		Callee:           raw.Callee,
+		CalleeShowNumber: raw.CalleeShowNumber,
+		HangupDirection:  raw.HangupDirection,
+	}
+	if raw.Duration != "" {
+		n, err := raw.Duration.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid call duration: %v", err)
+		}
+		detail.Duration = int(n)
+	}
+	return detail, nil
+}
+
+// ProdID specifies the product ID of the voice call to query.
+// It's ProdIDVoiceNotify by default if no one specified.
+// e.g. use ProdIDVoiceVerification for voice verification calls.
+func ProdID(ID string) Param {
+	return Param{f: func(v url.Values) { v.Set("ProdId", ID) }}
+}
+
+// QueryCallDetailByCallID queries the detail of the voice call. e.g. answered, busy, no answer.
+//
+// callID: ID of the call returned by MakeSingleCallByTTS() or MakeSingleCallByVoice().
+// queryDate: the date of the call as Unix timestamp in milliseconds. e.g. "1577836800000".
+// params: optional parameters for the query. e.g. ProdID().
+//
+// It returns success status, response and error.
+// The detail of the call is parsed from the JSON string of "Data" in the response.
+// The error is an *APIError if the status code of the response is not "OK".
+//
+// For example:
+//
+// c := message.NewClient(accessKeyID, accessKeySecret)
+//
+// ok, resp, err := c.QueryCallDetailByCallID("116012354148^10281378", "1577836800000")
+func (c *Client) QueryCallDetailByCallID(callID, queryDate string, params ...Param) (bool, *QueryCallDetailResponse, error) {
+	return c.QueryCallDetailByCallIDContext(context.Background(), callID, queryDate, params...)
+}
+
+// QueryCallDetailByCallIDContext queries the detail of the voice call with the context.
+// The HTTP request is aborted when the context is canceled or its deadline exceeds.
+// See QueryCallDetailByCallID() for other parameters.
+func (c *Client) QueryCallDetailByCallIDContext(ctx context.Context, callID, queryDate string, params ...Param) (bool, *QueryCallDetailResponse, error) {
+	v := url.Values{}
+	// Set default common parameters for aliyun services.
+	c.SetDefaultCommonParams(v)
+
+	// Set default business parameters for querying the call detail.
+	v.Set("Action", "QueryCallDetailByCallId")
+	v.Set("Version", voiceVersion)
+	v.Set("RegionId", DefaultRegionID)
+	v.Set("ProdId", ProdIDVoiceNotify)
+
+	// Set required business parameters
+	v.Set("CallId", callID)
+	v.Set("QueryDate", queryDate)
+
+	// Override parameters if need.
+	o, err := applyParams(v, params)
+	if err != nil {
+		return false, nil, err
+	}
+
+	response := &QueryCallDetailResponse{}
+	host, err := serviceHost(v, o, ServiceVoice)
+	if err != nil {
+		return false, nil, err
+	}
+	result, err := c.call(ctx, host, v, o, response)
+	if !result.parsed {
+		return false, nil, err
+	}
+	if err != nil || response.Data == "" {
+		return result.ok, response, err
+	}
+
+	// Parse the nested JSON string.
+	if response.Detail, err = parseCallDetail(response.Data); err != nil {
+		return false, response, err
+	}
+	return result.ok, response, nil
+}
+
+// defaultGroupCallConcurrency is the default max number of concurrent calls of MakeVoiceGroupCall().
+const defaultGroupCallConcurrency = 5
+
+// VoiceCallResult is the result of the call to a phone number of MakeVoiceGroupCall().
+type VoiceCallResult struct {
+	// CalledNumber is the phone number of the call.
+	CalledNumber string
+	// OK is the success status.
+	OK bool
+	// Response is the response. It's nil if the response can't be parsed.
+	Response *SingleCallByTTSResponse
+	// Err is the error of the call.
+	Err error
+}
+
+// MakeVoiceGroupCall makes single calls to play the voice file to the phone numbers concurrently.
+// e.g. outage notifications.
+//
+// calledShowNumbers: called show numbers for each phone number, or only one called show number shared by all phone numbers.
+// calledNumbers: phone numbers to make single calls.
+// voiceCode: permitted voice file ID shared by all calls.
+// params: optional parameters for all calls. e.g. PlayTimes(), Volume(), Speed().
+//
+// Calls are made by a pool of at most 5 workers by default. Specify the number of workers by GroupCallConcurrency().
+// They're also limited by WithMaxConcurrency() of the client.
+//
+// It returns results of calls in the same order of calledNumbers and error.
+// The error is not nil if the lengths of called show numbers and phone numbers mismatch or params are invalid.
+func (c *Client) MakeVoiceGroupCall(calledShowNumbers, calledNumbers []string, voiceCode string, params ...Param) ([]VoiceCallResult, error) {
+	return c.MakeVoiceGroupCallContext(context.Background(), calledShowNumbers, calledNumbers, voiceCode, params...)
+}
+
+// MakeVoiceGroupCallContext makes single calls to play the voice file to the phone numbers with the context.
+// Calls which are not started when the context is canceled get the error of the context.
+// See MakeVoiceGroupCall() for other parameters.
+func (c *Client) MakeVoiceGroupCallContext(ctx context.Context, calledShowNumbers, calledNumbers []string, voiceCode string, params ...Param) ([]VoiceCallResult, error) {
+	if len(calledShowNumbers) != 1 && len(calledShowNumbers) != len(calledNumbers) {
+		return nil, fmt.Errorf("lengths of called show numbers(%d) and called numbers(%d) mismatch",
+			len(calledShowNumbers), len(calledNumbers))
+	}
+
+	o, err := applyParams(url.Values{}, params)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := defaultGroupCallConcurrency
+	if o.groupCallConcurrency > 0 {
+		concurrency = o.groupCallConcurrency
+	}
+
+	results := make([]VoiceCallResult, len(calledNumbers))
+	runPool(len(calledNumbers), concurrency, func(i int) {
+		results[i].CalledNumber = calledNumbers[i]
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			return
+		}
+
+		calledShowNumber := calledShowNumbers[0]
+		if len(calledShowNumbers) > 1 {
+			calledShowNumber = calledShowNumbers[i]
+		}
+		results[i].OK, results[i].Response, results[i].Err = c.MakeSingleCallByVoiceContext(ctx, calledShowNumber, calledNumbers[i], voiceCode, params...)
+	})
+	return results, nil
+}
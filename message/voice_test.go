@@ -0,0 +1,262 @@
+package message_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/northbright/aliyun/message"
+)
+
+func TestMakeSingleCallByVoice(t *testing.T) {
+	var req *http.Request
+	client := message.NewClient("testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		return newStubResponse(http.StatusOK, `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK","CallId":"116012354148^10281378"}`), nil
+	})
+
+	timestamp, _ := time.Parse(time.RFC3339, "2017-07-12T02:42:19Z")
+	ok, resp, err := client.MakeSingleCallByVoice(
+		"02560000000",
+		"1500000000",
+		"voice.wav",
+		message.PlayTimes(2),
+		message.Timestamp(timestamp),
+		message.SignatureNonce("45e25e9b-0a6f-4070-8c85-2956eda1b466"),
+	)
+	if !ok || err != nil {
+		t.Fatalf("MakeSingleCallByVoice() = %v, %v, want true, nil", ok, err)
+	}
+	if resp.CallID != "116012354148^10281378" {
+		t.Errorf("CallID = %v, want 116012354148^10281378", resp.CallID)
+	}
+
+	if req.URL.Host != "dyvmsapi.aliyuncs.com" {
+		t.Errorf("host = %v, want dyvmsapi.aliyuncs.com", req.URL.Host)
+	}
+
+	params, signature := sentParams(req)
+	want := map[string]string{
+		"Action":           "SingleCallByVoice",
+		"CalledShowNumber": "02560000000",
+		"CalledNumber":     "1500000000",
+		"VoiceCode":        "voice.wav",
+		"PlayTimes":        "2",
+	}
+	for key, value := range want {
+		if params[key] != value {
+			t.Errorf("%v = %v, want %v", key, params[key], value)
+		}
+	}
+
+	// Known signature of the fixed timestamp and nonce.
+	if signature != "9R6susWUkBFkOLI29IjSZds0rP0=" {
+		t.Errorf("signature = %v, want %v", signature, "9R6susWUkBFkOLI29IjSZds0rP0=")
+	}
+}
+
+func TestQueryCallDetailByCallID(t *testing.T) {
+	tests := []struct {
+		format string
+		body   string
+	}{
+		{"JSON", `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK","Data":"{\"callId\":\"116012354148^10281378\",\"startDate\":\"2019-01-08 16:44:35\",\"state\":\"200000\",\"stateDesc\":\"用户接听\",\"endDate\":\"2019-01-08 16:45:03\",\"calleeShowNumber\":\"02560000000\",\"callee\":\"1500000000\",\"duration\":\"28\",\"hangupDirection\":\"0\"}"}`},
+		{"JSON", `{"RequestId":"8906582E-6722","Code":"OK","Message":"OK","Data":"{\"callId\":\"116012354148^10281378\",\"startDate\":\"2019-01-08 16:44:35\",\"state\":\"200000\",\"stateDesc\":\"用户接听\",\"endDate\":\"2019-01-08 16:45:03\",\"calleeShowNumber\":\"02560000000\",\"callee\":\"1500000000\",\"duration\":28,\"hangupDirection\":\"0\"}"}`},
+		{"XML", `<QueryCallDetailByCallIdResponse><RequestId>8906582E-6722</RequestId><Code>OK</Code><Message>OK</Message><Data>{"callId":"116012354148^10281378","startDate":"2019-01-08 16:44:35","state":"200000","stateDesc":"用户接听","endDate":"2019-01-08 16:45:03","calleeShowNumber":"02560000000","callee":"1500000000","duration":"28","hangupDirection":"0"}</Data></QueryCallDetailByCallIdResponse>`},
+	}
+
+	want := message.CallDetail{
+		CallID:           "116012354148^10281378",
+		State:            "200000",
+		StateDesc:        "用户接听",
+		Duration:         28,
+		StartTime:        "2019-01-08 16:44:35",
+		EndTime:          "2019-01-08 16:45:03",
+		Callee:           "1500000000",
+		CalleeShowNumber: "02560000000",
+		HangupDirection:  "0",
+	}
+
+	for _, tt := range tests {
+		var query url.Values
+		client := message.NewClient("my_key_id", "my_key_secret")
+		client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			return newStubResponse(http.StatusOK, tt.body), nil
+		})
+
+		ok, resp, err := client.QueryCallDetailByCallID("116012354148^10281378", "1546936800000", message.Format(tt.format))
+		if !ok || err != nil {
+			t.Fatalf("QueryCallDetailByCallID() = %v, %v, want true, nil", ok, err)
+		}
+		if resp.Detail == nil || *resp.Detail != want {
+			t.Errorf("Detail = %+v, want %+v", resp.Detail, want)
+		}
+
+		for key, value := range map[string]string{"Action": "QueryCallDetailByCallId", "CallId": "116012354148^10281378", "QueryDate": "1546936800000", "ProdId": message.ProdIDVoiceNotify} {
+			if got := query.Get(key); got != value {
+				t.Errorf("%v = %v, want %v", key, got, value)
+			}
+		}
+	}
+}
+
+func TestQueryCallDetailByCallIDInvalidData(t *testing.T) {
+	client := message.NewClient("my_key_id", "my_key_secret")
+	client.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return newStubResponse(http.StatusOK, `{"Code":"OK","Data":"{\"callId\":"}`), nil
+	})
+
+	ok, resp, err := client.QueryCallDetailByCallID("116012354148^10281378", "1546936800000", message.ProdID(message.ProdIDVoiceVerification))
+	if ok || err == nil {
+		t.Errorf("QueryCallDetailByCallID() = %v, %v, want false and error", ok, err)
+	}
+	if resp == nil || resp.Data != `{"callId":` {
+		t.Errorf("QueryCallDetailByCallID() response = %+v, want the raw data", resp)
+	}
+}
+
+func TestMakeVoiceGroupCall(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]string{}
+	client := message.NewClient("testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		query := r.URL.Query()
+		calledNumber := query.Get("CalledNumber")
+		mu.Lock()
+		calls[calledNumber] = query.Get("CalledShowNumber")
+		mu.Unlock()
+
+		if calledNumber == "1500000002" {
+			return newStubResponse(http.StatusOK, `{"Code":"isv.MOBILE_NUMBER_ILLEGAL"}`), nil
+		}
+		return newStubResponse(http.StatusOK, fmt.Sprintf(`{"Code":"OK","CallId":"call-%s"}`, calledNumber)), nil
+	})
+
+	calledNumbers := []string{}
+	for i := 0; i < 8; i++ {
+		calledNumbers = append(calledNumbers, fmt.Sprintf("150000000%d", i))
+	}
+
+	tests := []struct {
+		calledShowNumbers []string
+		wantShowNumber    func(i int) string
+	}{
+		// Shared called show number.
+		{[]string{"02560000000"}, func(i int) string { return "02560000000" }},
+		// Called show number for each phone number.
+		{calledNumbers, func(i int) string { return calledNumbers[i] }},
+	}
+
+	for _, tt := range tests {
+		results, err := client.MakeVoiceGroupCall(tt.calledShowNumbers, calledNumbers, "voice.wav")
+		if err != nil {
+			t.Fatalf("MakeVoiceGroupCall() error: %v", err)
+		}
+		if len(results) != len(calledNumbers) {
+			t.Fatalf("len(results) = %v, want %v", len(results), len(calledNumbers))
+		}
+
+		for i, r := range results {
+			if r.CalledNumber != calledNumbers[i] {
+				t.Errorf("results[%d].CalledNumber = %v, want %v", i, r.CalledNumber, calledNumbers[i])
+			}
+			if got := calls[calledNumbers[i]]; got != tt.wantShowNumber(i) {
+				t.Errorf("CalledShowNumber of %v = %v, want %v", calledNumbers[i], got, tt.wantShowNumber(i))
+			}
+
+			if calledNumbers[i] == "1500000002" {
+				var apiErr *message.APIError
+				if r.OK || !errors.As(r.Err, &apiErr) {
+					t.Errorf("results[%d] = %v, %v, want *message.APIError", i, r.OK, r.Err)
+				}
+				continue
+			}
+			if !r.OK || r.Err != nil || r.Response.CallID != "call-"+calledNumbers[i] {
+				t.Errorf("results[%d] = %v, %v, %v, want OK response", i, r.OK, r.Response, r.Err)
+			}
+		}
+	}
+}
+
+func TestGroupCallConcurrency(t *testing.T) {
+	tests := []struct {
+		params []message.Param
+		want   int32
+	}{
+		{nil, 5},
+		{[]message.Param{message.GroupCallConcurrency(2)}, 2},
+		{[]message.Param{message.GroupCallConcurrency(0)}, 5},
+	}
+
+	for _, tt := range tests {
+		var inFlight, maxInFlight int32
+		client := message.NewClient("testId", "testSecret")
+		client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if _, ok := r.URL.Query()["GroupCallConcurrency"]; ok {
+				t.Errorf("GroupCallConcurrency is sent to aliyun")
+			}
+
+			cur := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if cur <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+					break
+				}
+			}
+
+			// Block for a while to make calls overlap.
+			time.Sleep(20 * time.Millisecond)
+			return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+		})
+
+		calledNumbers := []string{}
+		for i := 0; i < 10; i++ {
+			calledNumbers = append(calledNumbers, fmt.Sprintf("150000000%02d", i))
+		}
+		if _, err := client.MakeVoiceGroupCall([]string{"02560000000"}, calledNumbers, "voice.wav", tt.params...); err != nil {
+			t.Fatalf("MakeVoiceGroupCall() error: %v", err)
+		}
+		if maxInFlight > tt.want {
+			t.Errorf("max in-flight calls = %v, want <= %v", maxInFlight, tt.want)
+		}
+	}
+}
+
+func TestMakeVoiceGroupCallError(t *testing.T) {
+	requests := 0
+	client := message.NewClient("testId", "testSecret")
+	client.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return newStubResponse(http.StatusOK, `{"Code":"OK"}`), nil
+	})
+
+	// Lengths mismatch.
+	if _, err := client.MakeVoiceGroupCall([]string{"02560000000", "02560000001"}, []string{"1500000000", "1500000001", "1500000002"}, "voice.wav"); err == nil {
+		t.Errorf("MakeVoiceGroupCall() with mismatched lengths returns no error")
+	}
+
+	// Context is canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := client.MakeVoiceGroupCallContext(ctx, []string{"02560000000"}, []string{"1500000000", "1500000001"}, "voice.wav")
+	if err != nil {
+		t.Fatalf("MakeVoiceGroupCallContext() error: %v", err)
+	}
+	for i, r := range results {
+		if r.OK || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("results[%d] = %v, %v, want %v", i, r.OK, r.Err, context.Canceled)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("requests = %v, want 0", requests)
+	}
+}